@@ -0,0 +1,79 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Attributes are additional attributes of an authorization request,
+// e.g. the AuthZEN context or properties of the request.
+type Attributes map[string]interface{}
+
+// WithAttributes returns a new context with the attributes.
+// The attributes are merged with the attributes already in the context.
+func WithAttributes(ctx context.Context, attrs Attributes) context.Context {
+	merged := Attributes{}
+
+	for k, v := range GetAttributes(ctx) {
+		merged[k] = v
+	}
+
+	for k, v := range attrs {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, authzAttributes, merged)
+}
+
+// GetAttributes returns the attributes from the context.
+// Returns empty attributes if there are none.
+func GetAttributes(ctx context.Context) Attributes {
+	attrs, ok := ctx.Value(authzAttributes).(Attributes)
+	if !ok {
+		return Attributes{}
+	}
+
+	return attrs
+}
+
+// RequestAttributes returns the method and path of a request as attributes,
+// e.g. input.attributes.request.method for OPA.
+func RequestAttributes(method, path string) Attributes {
+	return Attributes{
+		"request": map[string]interface{}{
+			"method": method,
+			"path":   path,
+		},
+	}
+}
+
+// AuthzAttributesResolver is the interface that wraps the Resolve method.
+type AuthzAttributesResolver interface {
+	// Resolve returns the attributes of the request.
+	Resolve(c *fiber.Ctx) (Attributes, error)
+}
+
+type requestAttributesResolver struct{}
+
+// Resolve ...
+func (r *requestAttributesResolver) Resolve(c *fiber.Ctx) (Attributes, error) {
+	return RequestAttributes(c.Method(), c.Path()), nil
+}
+
+// NewRequestAttributesResolver returns a resolver of the method and path of the request.
+func NewRequestAttributesResolver() AuthzAttributesResolver {
+	return &requestAttributesResolver{}
+}
+
+type noopAttributesResolver struct{}
+
+// Resolve ...
+func (n *noopAttributesResolver) Resolve(c *fiber.Ctx) (Attributes, error) {
+	return Attributes{}, nil
+}
+
+// NewNoopAttributesResolver returns a resolver without attributes.
+func NewNoopAttributesResolver() AuthzAttributesResolver {
+	return &noopAttributesResolver{}
+}
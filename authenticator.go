@@ -9,16 +9,25 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3filter"
 	"github.com/lestrrat-go/jwx/jwt"
+	middleware "github.com/oapi-codegen/fiber-middleware"
+	"github.com/zeiss/fiber-authz/oas"
 )
 
 const (
 	PermissionsClaim = "perms"
 )
 
+// DefaultSecuritySchemeName is the default name of the security scheme of the authenticator.
+const DefaultSecuritySchemeName = "BearerAuth"
+
+// DefaultPrincipalClaim is the default claim of the principal.
+const DefaultPrincipalClaim = "sub"
+
 var (
 	ErrNoAuthHeader      = errors.New("authorization header is missing")
 	ErrInvalidAuthHeader = errors.New("authorization header is malformed")
 	ErrClaimsInvalid     = errors.New("provided claims do not match expected scopes")
+	ErrNoPrincipalClaim  = errors.New("principal claim is missing")
 )
 
 // JWSValidator ...
@@ -26,20 +35,175 @@ type JWSValidator interface {
 	ValidateJWS(jws string) (jwt.Token, error)
 }
 
+// AuthenticatorObjectResolver resolves the object of the authentication input.
+type AuthenticatorObjectResolver func(input *openapi3filter.AuthenticationInput) (AuthzObject, error)
+
+// AuthenticatorActionResolver resolves the action of the authentication input.
+type AuthenticatorActionResolver func(input *openapi3filter.AuthenticationInput) (AuthzAction, error)
+
+// AuthenticatorAttributesResolver resolves the attributes of the authentication input and the validated token.
+type AuthenticatorAttributesResolver func(input *openapi3filter.AuthenticationInput, token jwt.Token) (Attributes, error)
+
+// AuthenticatorOpts are the options of the authenticator.
+type AuthenticatorOpts struct {
+	// SecuritySchemeName is the name of the security scheme.
+	SecuritySchemeName string
+	// PrincipalClaim is the claim of the principal.
+	PrincipalClaim string
+	// ObjectResolver resolves the object.
+	ObjectResolver AuthenticatorObjectResolver
+	// ActionResolver resolves the action.
+	ActionResolver AuthenticatorActionResolver
+	// AttributesResolver resolves the attributes, e.g. the input of OPA.
+	AttributesResolver AuthenticatorAttributesResolver
+	// Claims are the options for extracting the permissions from the token.
+	Claims []oas.ClaimsOpt
+}
+
+// Configure the authenticator.
+func (o *AuthenticatorOpts) Configure(opts ...AuthenticatorOpt) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// AuthenticatorOpt is a function that sets an option on the authenticator.
+type AuthenticatorOpt func(*AuthenticatorOpts)
+
+// AuthenticatorDefaultOpts are the default authenticator options.
+func AuthenticatorDefaultOpts() AuthenticatorOpts {
+	return AuthenticatorOpts{
+		SecuritySchemeName: DefaultSecuritySchemeName,
+		PrincipalClaim:     DefaultPrincipalClaim,
+		ObjectResolver:     RoutePathObjectResolver,
+		ActionResolver:     OperationActionResolver,
+		AttributesResolver: RequestAttributesResolver,
+	}
+}
+
+// WithSecuritySchemeName sets the name of the security scheme.
+func WithSecuritySchemeName(name string) AuthenticatorOpt {
+	return func(o *AuthenticatorOpts) {
+		o.SecuritySchemeName = name
+	}
+}
+
+// WithPrincipalClaim sets the claim of the principal.
+func WithPrincipalClaim(claim string) AuthenticatorOpt {
+	return func(o *AuthenticatorOpts) {
+		o.PrincipalClaim = claim
+	}
+}
+
+// WithAuthenticatorObjectResolver sets the object resolver.
+func WithAuthenticatorObjectResolver(resolver AuthenticatorObjectResolver) AuthenticatorOpt {
+	return func(o *AuthenticatorOpts) {
+		o.ObjectResolver = resolver
+	}
+}
+
+// WithAuthenticatorActionResolver sets the action resolver.
+func WithAuthenticatorActionResolver(resolver AuthenticatorActionResolver) AuthenticatorOpt {
+	return func(o *AuthenticatorOpts) {
+		o.ActionResolver = resolver
+	}
+}
+
+// WithAuthenticatorAttributesResolver sets the attributes resolver.
+func WithAuthenticatorAttributesResolver(resolver AuthenticatorAttributesResolver) AuthenticatorOpt {
+	return func(o *AuthenticatorOpts) {
+		o.AttributesResolver = resolver
+	}
+}
+
+// WithTokenClaims sets the options for extracting the permissions from the token.
+func WithTokenClaims(opts ...oas.ClaimsOpt) AuthenticatorOpt {
+	return func(o *AuthenticatorOpts) {
+		o.Claims = opts
+	}
+}
+
+// RoutePathObjectResolver returns the path of the route with the values of the path parameters.
+// The template is substituted in a single pass, so values are never expanded again.
+func RoutePathObjectResolver(input *openapi3filter.AuthenticationInput) (AuthzObject, error) {
+	template := input.RequestValidationInput.Route.Path
+	params := input.RequestValidationInput.PathParams
+
+	var b strings.Builder
+
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			break
+		}
+
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+
+		end += start
+		b.WriteString(template[:start])
+
+		if value, ok := params[template[start+1:end]]; ok {
+			b.WriteString(value)
+		} else {
+			b.WriteString(template[start : end+1])
+		}
+
+		template = template[end+1:]
+	}
+
+	b.WriteString(template)
+
+	return AuthzObject(b.String()), nil
+}
+
+// OperationActionResolver returns the operation id of the route, or the method if it has none.
+func OperationActionResolver(input *openapi3filter.AuthenticationInput) (AuthzAction, error) {
+	route := input.RequestValidationInput.Route
+
+	if route.Operation != nil && route.Operation.OperationID != "" {
+		return AuthzAction(route.Operation.OperationID), nil
+	}
+
+	return AuthzAction(route.Method), nil
+}
+
+// RequestAttributesResolver returns the method and path of the request.
+func RequestAttributesResolver(input *openapi3filter.AuthenticationInput, _ jwt.Token) (Attributes, error) {
+	req := input.RequestValidationInput.Request
+
+	return RequestAttributes(req.Method, req.URL.Path), nil
+}
+
 // NewAuthenticator ...
-func NewAuthenticator(c AuthzChecker, v JWSValidator) openapi3filter.AuthenticationFunc {
+func NewAuthenticator(c AuthzChecker, v JWSValidator, opts ...AuthenticatorOpt) openapi3filter.AuthenticationFunc {
+	options := AuthenticatorDefaultOpts()
+	options.Configure(opts...)
+
 	return func(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
-		return Authenticated(ctx, c, v, input)
+		return authenticated(ctx, c, v, input, options)
 	}
 }
 
 // ErrForbidden ...
 var ErrForbidden = errors.New("forbidden")
 
-// Authenticated ...
-func Authenticated(ctx context.Context, checker AuthzChecker, validate JWSValidator, input *openapi3filter.AuthenticationInput) error {
-	if input.SecuritySchemeName != "BearerAuth" {
-		return fmt.Errorf("security scheme %s != 'BearerAuth'", input.SecuritySchemeName)
+// Authenticated validates the token of the request and checks if the principal of the token
+// is allowed to perform the action on the object of the operation.
+// The token and the decision are set on the user context of the fiber context.
+// Use NewAuthenticator to configure the options once.
+func Authenticated(ctx context.Context, checker AuthzChecker, validate JWSValidator, input *openapi3filter.AuthenticationInput, opts ...AuthenticatorOpt) error {
+	options := AuthenticatorDefaultOpts()
+	options.Configure(opts...)
+
+	return authenticated(ctx, checker, validate, input, options)
+}
+
+func authenticated(ctx context.Context, checker AuthzChecker, validate JWSValidator, input *openapi3filter.AuthenticationInput, options AuthenticatorOpts) error {
+	if input.SecuritySchemeName != options.SecuritySchemeName {
+		return fmt.Errorf("security scheme %s != '%s'", input.SecuritySchemeName, options.SecuritySchemeName)
 	}
 
 	jws, err := GetJWSFromRequest(input.RequestValidationInput.Request)
@@ -52,46 +216,92 @@ func Authenticated(ctx context.Context, checker AuthzChecker, validate JWSValida
 		return fmt.Errorf("validating JWS: %w", err)
 	}
 
-	err = CheckTokenClaims(input.Scopes, token)
+	err = CheckTokenClaims(input.Scopes, token, options.Claims...)
 	if err != nil {
 		return fmt.Errorf("token claims don't match: %w", err)
 	}
 
-	// allowed, err := checker.Allowed(ctx)
-	// if err != nil {
-	// 	return ErrForbidden
-	// }
+	principal, err := GetPrincipalFromToken(token, options.PrincipalClaim)
+	if err != nil {
+		return err
+	}
 
-	// if allowed {
-	// 	return nil
-	// }
+	object, err := options.ObjectResolver(input)
+	if err != nil {
+		return fmt.Errorf("resolving object: %w", err)
+	}
 
-	return ErrForbidden
-}
+	action, err := options.ActionResolver(input)
+	if err != nil {
+		return fmt.Errorf("resolving action: %w", err)
+	}
+
+	attrs, err := options.AttributesResolver(input, token)
+	if err != nil {
+		return fmt.Errorf("resolving attributes: %w", err)
+	}
+
+	decision, err := Decide(WithAttributes(ctx, attrs), checker, principal, object, action)
+	if err != nil {
+		return fmt.Errorf("checking authorization: %w", err)
+	}
+
+	if c := middleware.GetFiberContext(ctx); c != nil {
+		usrCtx := WithToken(c.UserContext(), token)
+		usrCtx = WithAuthzDecision(usrCtx, NewAuthzContext(principal, object, action), decision)
 
-// GetClaimsFromToken ...
-func GetClaimsFromToken(t jwt.Token) ([]string, error) {
-	rawPerms, found := t.Get(PermissionsClaim)
-	if !found {
-		return make([]string, 0), nil
+		// nolint: contextcheck
+		c.SetUserContext(usrCtx)
 	}
 
-	rawList, ok := rawPerms.([]interface{})
+	if !decision.Allowed {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+type tokenKey struct{}
+
+// WithToken returns a new context with the validated token.
+func WithToken(ctx context.Context, token jwt.Token) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+// GetToken returns the validated token from the context.
+func GetToken(ctx context.Context) (jwt.Token, bool) {
+	token, ok := ctx.Value(tokenKey{}).(jwt.Token)
+
+	return token, ok
+}
+
+// GetPrincipalFromToken returns the principal from the claim of the token.
+func GetPrincipalFromToken(t jwt.Token, claim string) (AuthzPrincipal, error) {
+	v, ok := t.Get(claim)
 	if !ok {
-		return nil, fmt.Errorf("'%s' claim is unexpected type'", PermissionsClaim)
+		return AuthzNoPrincipial, ErrNoPrincipalClaim
 	}
 
-	claims := make([]string, len(rawList))
+	s, ok := v.(string)
+	if !ok || s == "" {
+		return AuthzNoPrincipial, fmt.Errorf("%w: %s is not a string", ErrNoPrincipalClaim, claim)
+	}
 
-	for i, rawClaim := range rawList {
-		var ok bool
-		claims[i], ok = rawClaim.(string)
-		if !ok {
-			return nil, fmt.Errorf("%s[%d] is not a string", PermissionsClaim, i)
-		}
+	return AuthzPrincipal(s), nil
+}
+
+// GetClaimsFromToken returns the permissions of the token.
+// By default the permissions are the perms claim. oas.WithClaimPaths replaces the perms claim,
+// oas.WithAdditionalClaimPaths merges other claims with it.
+func GetClaimsFromToken(t jwt.Token, opts ...oas.ClaimsOpt) ([]string, error) {
+	claims, err := t.AsMap(context.Background())
+	if err != nil {
+		return nil, err
 	}
 
-	return claims, nil
+	opts = append([]oas.ClaimsOpt{oas.WithClaimPaths(PermissionsClaim)}, opts...)
+
+	return oas.ExtractClaims(claims, opts...)
 }
 
 // GetJWSFromRequest ...
@@ -112,9 +322,9 @@ func GetJWSFromRequest(req *http.Request) (string, error) {
 	return strings.TrimPrefix(authHdr, prefix), nil
 }
 
-// CheckTokenClaims ...
-func CheckTokenClaims(expectedClaims []string, t jwt.Token) error {
-	claims, err := GetClaimsFromToken(t)
+// CheckTokenClaims returns ErrClaimsInvalid if the token does not have all expected claims.
+func CheckTokenClaims(expectedClaims []string, t jwt.Token, opts ...oas.ClaimsOpt) error {
+	claims, err := GetClaimsFromToken(t, opts...)
 	if err != nil {
 		return fmt.Errorf("getting claims from token: %w", err)
 	}
@@ -0,0 +1,159 @@
+package authz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/lestrrat-go/jwx/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+type tokenValidator struct {
+	token jwt.Token
+}
+
+func (v *tokenValidator) ValidateJWS(_ string) (jwt.Token, error) {
+	return v.token, nil
+}
+
+type recordingChecker struct {
+	params AuthzParams
+}
+
+func (r *recordingChecker) Allowed(_ context.Context, principal AuthzPrincipal, object AuthzObject, action AuthzAction) (bool, error) {
+	r.params = AuthzParams{Principal: principal, Object: object, Action: action}
+
+	return principal == "alice", nil
+}
+
+func TestAuthenticated(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		opts   []AuthenticatorOpt
+		scheme string
+		params AuthzParams
+		err    error
+	}{
+		{
+			name:   "subject",
+			claims: map[string]interface{}{jwt.SubjectKey: "alice"},
+			scheme: DefaultSecuritySchemeName,
+			params: AuthzParams{Principal: "alice", Object: "/teams/zeiss", Action: "getTeam"},
+		},
+		{
+			name:   "claim",
+			claims: map[string]interface{}{jwt.SubjectKey: "1234", "email": "alice"},
+			opts:   []AuthenticatorOpt{WithPrincipalClaim("email"), WithSecuritySchemeName("OAuth2")},
+			scheme: "OAuth2",
+			params: AuthzParams{Principal: "alice", Object: "/teams/zeiss", Action: "getTeam"},
+		},
+		{
+			name:   "forbidden",
+			claims: map[string]interface{}{jwt.SubjectKey: "bob"},
+			scheme: DefaultSecuritySchemeName,
+			params: AuthzParams{Principal: "bob", Object: "/teams/zeiss", Action: "getTeam"},
+			err:    ErrForbidden,
+		},
+		{
+			name:   "no principal",
+			claims: map[string]interface{}{},
+			scheme: DefaultSecuritySchemeName,
+			err:    ErrNoPrincipalClaim,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			token := jwt.New()
+			for k, v := range tt.claims {
+				require.NoError(t, token.Set(k, v))
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/teams/zeiss", nil)
+			req.Header.Set("Authorization", "Bearer token")
+
+			input := &openapi3filter.AuthenticationInput{
+				SecuritySchemeName: tt.scheme,
+				RequestValidationInput: &openapi3filter.RequestValidationInput{
+					Request:    req,
+					PathParams: map[string]string{"team": "zeiss"},
+					Route: &routers.Route{
+						Path:      "/teams/{team}",
+						Method:    http.MethodGet,
+						Operation: &openapi3.Operation{OperationID: "getTeam"},
+					},
+				},
+			}
+
+			checker := &recordingChecker{}
+
+			err := Authenticated(context.Background(), checker, &tokenValidator{token}, input, tt.opts...)
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.params, checker.params)
+		})
+	}
+}
+
+func TestRoutePathObjectResolver(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		path   string
+		params map[string]string
+		object AuthzObject
+	}{
+		{
+			name:   "params",
+			path:   "/teams/{teamId}/workloads/{workloadId}",
+			params: map[string]string{"teamId": "zeiss", "workloadId": "1"},
+			object: "/teams/zeiss/workloads/1",
+		},
+		{
+			name:   "value with a param",
+			path:   "/teams/{teamId}/workloads/{workloadId}",
+			params: map[string]string{"teamId": "{workloadId}", "workloadId": "{teamId}"},
+			object: "/teams/{workloadId}/workloads/{teamId}",
+		},
+		{
+			name:   "param in a segment",
+			path:   "/files/{fileId}.json",
+			params: map[string]string{"fileId": "a"},
+			object: "/files/a.json",
+		},
+		{
+			name:   "missing param",
+			path:   "/teams/{teamId}",
+			params: map[string]string{},
+			object: "/teams/{teamId}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// repeated to cover the random order of the params.
+			for range 10 {
+				object, err := RoutePathObjectResolver(&openapi3filter.AuthenticationInput{
+					RequestValidationInput: &openapi3filter.RequestValidationInput{
+						Route:      &routers.Route{Path: tt.path},
+						PathParams: tt.params,
+					},
+				})
+				require.NoError(t, err)
+				require.Equal(t, tt.object, object)
+			}
+		})
+	}
+}
@@ -6,6 +6,9 @@ package authz
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -67,17 +70,32 @@ const (
 	authzObject
 	authzAction
 	authzContext
+	authzDecision
+	authzAttributes
 )
 
 // Unimplemented is the default implementation.
 type Unimplemented struct{}
 
 // Allowed is the default implementation.
-func (u *Unimplemented) Allowed(_ context.Context, _ AuthzPrincipal, _ AuthzObject, _ AuthzAction) (bool, error) {
-	return false, nil
+func (u *Unimplemented) Allowed(ctx context.Context, principal AuthzPrincipal, object AuthzObject, action AuthzAction) (bool, error) {
+	decision, err := u.Decide(ctx, principal, object, action)
+	if err != nil {
+		return false, err
+	}
+
+	return decision.Allowed, nil
 }
 
-var _ AuthzChecker = (*Fake)(nil)
+// Decide is the default implementation.
+func (u *Unimplemented) Decide(_ context.Context, _ AuthzPrincipal, _ AuthzObject, _ AuthzAction) (Decision, error) {
+	return Deny("unimplemented", "not implemented"), nil
+}
+
+var (
+	_ AuthzChecker    = (*Fake)(nil)
+	_ DecisionChecker = (*Fake)(nil)
+)
 
 // Fake is a fake authz checker.
 type Fake struct {
@@ -90,8 +108,22 @@ func NewFake(allowed bool) *Fake {
 }
 
 // Allowed returns true if the principal is allowed to perform the action on the object.
-func (f *Fake) Allowed(_ context.Context, _ AuthzPrincipal, _ AuthzObject, _ AuthzAction) (bool, error) {
-	return f.allowd, nil
+func (f *Fake) Allowed(ctx context.Context, principal AuthzPrincipal, object AuthzObject, action AuthzAction) (bool, error) {
+	decision, err := f.Decide(ctx, principal, object, action)
+	if err != nil {
+		return false, err
+	}
+
+	return decision.Allowed, nil
+}
+
+// Decide returns the configured decision.
+func (f *Fake) Decide(_ context.Context, _ AuthzPrincipal, _ AuthzObject, _ AuthzAction) (Decision, error) {
+	if f.allowd {
+		return Allow("fake", "fake allows all"), nil
+	}
+
+	return Deny("fake", "fake denies all"), nil
 }
 
 // Config ...
@@ -111,19 +143,34 @@ type Config struct {
 	// PrincipalResolver is the principal resolver.
 	PrincipalResolver AuthzPrincipalResolver
 
+	// AttributesResolver resolves the attributes of the request, e.g. the input of OPA.
+	//
+	// Optional. Default: NewRequestAttributesResolver()
+	AttributesResolver AuthzAttributesResolver
+
 	// ErrorHandler is executed when an error is returned from fiber.Handler.
 	//
 	// Optional. Default: DefaultErrorHandler
 	ErrorHandler fiber.ErrorHandler
+
+	// MaxBatchSize is the maximum number of checks in a batch.
+	//
+	// Optional. Default: DefaultMaxBatchSize
+	MaxBatchSize int
 }
 
+// DefaultMaxBatchSize is the default maximum number of checks in a batch.
+const DefaultMaxBatchSize = 100
+
 // ConfigDefault is the default config.
 var ConfigDefault = Config{
-	ErrorHandler:      defaultErrorHandler,
-	ObjectResolver:    NewNoopObjectResolver(),
-	PrincipalResolver: NewNoopPrincipalResolver(),
-	ActionResolver:    NewNoopActionResolver(),
-	Checker:           NewNoop(),
+	ErrorHandler:       defaultErrorHandler,
+	ObjectResolver:     NewNoopObjectResolver(),
+	PrincipalResolver:  NewNoopPrincipalResolver(),
+	ActionResolver:     NewNoopActionResolver(),
+	AttributesResolver: NewRequestAttributesResolver(),
+	Checker:            NewNoop(),
+	MaxBatchSize:       DefaultMaxBatchSize,
 }
 
 // default ErrorHandler that process return error from fiber.Handler
@@ -131,6 +178,31 @@ func defaultErrorHandler(_ *fiber.Ctx, _ error) error {
 	return fiber.ErrBadRequest
 }
 
+// ErrorResponse is the JSON representation of an error.
+type ErrorResponse struct {
+	// Code is the HTTP status code.
+	Code int `json:"code"`
+	// Message is the error message.
+	Message string `json:"message"`
+}
+
+// JSONErrorHandler is an ErrorHandler that responds with an ErrorResponse.
+// Errors that are not a *fiber.Error are reported as internal server error.
+func JSONErrorHandler(c *fiber.Ctx, err error) error {
+	res := ErrorResponse{
+		Code:    fiber.StatusInternalServerError,
+		Message: "internal server error",
+	}
+
+	var e *fiber.Error
+	if errors.As(err, &e) {
+		res.Code = e.Code
+		res.Message = e.Message
+	}
+
+	return c.Status(res.Code).JSON(res)
+}
+
 // AuthzObjectResolver is the interface that wraps the Resolve method.
 type AuthzObjectResolver interface {
 	// Resolve ...
@@ -173,12 +245,22 @@ func Authenticate(handler fiber.Handler, config ...Config) fiber.Handler {
 			return err
 		}
 
-		allowed, err := cfg.Checker.Allowed(c.Context(), principal, object, action)
+		attrs, err := cfg.AttributesResolver.Resolve(c)
+		if err != nil {
+			return err
+		}
+
+		// The user context carries the contextual data that the resolvers have set.
+		// nolint: contextcheck
+		decision, err := Decide(WithAttributes(c.UserContext(), attrs), cfg.Checker, principal, object, action)
 		if err != nil {
 			return cfg.ErrorHandler(c, err)
 		}
 
-		if !allowed {
+		// nolint: contextcheck
+		c.SetUserContext(WithAuthzDecision(c.UserContext(), NewAuthzContext(principal, object, action), decision))
+
+		if !decision.Allowed {
 			return c.SendStatus(403)
 		}
 
@@ -218,6 +300,86 @@ func NewCheckerHandler(config ...Config) fiber.Handler {
 	}
 }
 
+// BatchCheck is a single check of a batch request.
+type BatchCheck struct {
+	// ID is the correlation ID of the check.
+	ID string `json:"id"`
+
+	AuthzParams
+}
+
+// BatchCheckRequest is the payload of a batch request.
+type BatchCheckRequest struct {
+	// Checks are the checks of the batch.
+	Checks []BatchCheck `json:"checks"`
+}
+
+// BatchCheckResult is the result of a single check of a batch request.
+type BatchCheckResult struct {
+	// ID is the correlation ID of the check.
+	ID string `json:"id"`
+
+	Decision
+}
+
+// BatchCheckResponse is the response of a batch request.
+type BatchCheckResponse struct {
+	// Results are the results of the checks in the order of the request.
+	Results []BatchCheckResult `json:"results"`
+}
+
+// NewBatchCheckerHandler returns a new fiber.Handler that checks a batch of principals, objects and actions.
+// Checks without an ID are correlated by their index in the batch.
+// Errors are reported with the JSONErrorHandler unless an ErrorHandler is configured.
+func NewBatchCheckerHandler(config ...Config) fiber.Handler {
+	cfg := jsonConfigDefault(config...)
+
+	return func(c *fiber.Ctx) error {
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		payload := BatchCheckRequest{}
+
+		if err := c.BodyParser(&payload); err != nil {
+			return cfg.ErrorHandler(c, fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("error parsing body: %w", err).Error()))
+		}
+
+		if len(payload.Checks) == 0 {
+			return cfg.ErrorHandler(c, fiber.NewError(fiber.StatusBadRequest, "no checks provided"))
+		}
+
+		if len(payload.Checks) > cfg.MaxBatchSize {
+			return cfg.ErrorHandler(c, fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("batch size %d exceeds maximum of %d", len(payload.Checks), cfg.MaxBatchSize)))
+		}
+
+		checks := make([]AuthzParams, len(payload.Checks))
+		for i, check := range payload.Checks {
+			checks[i] = check.AuthzParams
+		}
+
+		decisions, err := BatchDecide(c.Context(), cfg.Checker, checks)
+		if err != nil {
+			return cfg.ErrorHandler(c, err)
+		}
+
+		res := BatchCheckResponse{
+			Results: make([]BatchCheckResult, len(decisions)),
+		}
+
+		for i, decision := range decisions {
+			id := payload.Checks[i].ID
+			if id == "" {
+				id = strconv.Itoa(i)
+			}
+
+			res.Results[i] = BatchCheckResult{ID: id, Decision: decision}
+		}
+
+		return c.JSON(res)
+	}
+}
+
 // Helper function to set default values
 func configDefault(config ...Config) Config {
 	if len(config) < 1 {
@@ -247,6 +409,25 @@ func configDefault(config ...Config) Config {
 		cfg.ActionResolver = ConfigDefault.ActionResolver
 	}
 
+	if cfg.AttributesResolver == nil {
+		cfg.AttributesResolver = ConfigDefault.AttributesResolver
+	}
+
+	if cfg.MaxBatchSize <= 0 {
+		cfg.MaxBatchSize = ConfigDefault.MaxBatchSize
+	}
+
+	return cfg
+}
+
+// Helper function to set default values for handlers that respond with JSON errors
+func jsonConfigDefault(config ...Config) Config {
+	cfg := configDefault(config...)
+
+	if len(config) < 1 || config[0].ErrorHandler == nil {
+		cfg.ErrorHandler = JSONErrorHandler
+	}
+
 	return cfg
 }
 
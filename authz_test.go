@@ -2,8 +2,13 @@ package authz
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/require"
 )
 
@@ -49,3 +54,135 @@ func TestFakeChecker(t *testing.T) {
 		})
 	}
 }
+
+func TestDecide(t *testing.T) {
+	t.Parallel()
+
+	decision, err := Decide(context.TODO(), NewFake(true), "principal", "object", "action")
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+	require.Equal(t, "fake", decision.Checker)
+
+	decision, err = Decide(context.TODO(), NewNoop(), "principal", "object", "action")
+	require.NoError(t, err)
+	require.False(t, decision.Allowed)
+	require.Equal(t, "unimplemented", decision.Checker)
+}
+
+func TestAuthenticateDecision(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/", Authenticate(func(c *fiber.Ctx) error {
+		decision, err := GetAuthzDecision(c.UserContext())
+		if err != nil {
+			return err
+		}
+
+		return c.SendString(decision.Reason)
+	}, Config{Checker: NewFake(true)}))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "fake allows all", string(body))
+}
+
+type attributesChecker struct {
+	attrs Attributes
+}
+
+func (a *attributesChecker) Allowed(ctx context.Context, _ AuthzPrincipal, _ AuthzObject, _ AuthzAction) (bool, error) {
+	a.attrs = GetAttributes(ctx)
+
+	return true, nil
+}
+
+func TestAuthenticateAttributes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		resolver AuthzAttributesResolver
+		attrs    Attributes
+	}{
+		{
+			name:  "request attributes",
+			attrs: RequestAttributes(http.MethodGet, "/teams/zeiss"),
+		},
+		{
+			name:     "noop",
+			resolver: NewNoopAttributesResolver(),
+			attrs:    Attributes{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			checker := &attributesChecker{}
+
+			app := fiber.New()
+			app.Get("/teams/:team", Authenticate(func(c *fiber.Ctx) error {
+				return c.SendStatus(http.StatusOK)
+			}, Config{Checker: checker, AttributesResolver: tt.resolver}))
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/teams/zeiss", nil))
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, tt.attrs, checker.attrs)
+		})
+	}
+}
+
+func TestBatchCheckerHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		out    string
+	}{
+		{
+			name:   "results",
+			body:   `{"checks":[{"id":"a","principal":"p","object":"o","action":"read"},{"principal":"p","object":"o","action":"write"}]}`,
+			status: http.StatusOK,
+			out:    `{"results":[{"id":"a","allowed":true,"reason":"fake allows all","checker":"fake"},{"id":"1","allowed":true,"reason":"fake allows all","checker":"fake"}]}`,
+		},
+		{
+			name:   "empty batch",
+			body:   `{"checks":[]}`,
+			status: http.StatusBadRequest,
+			out:    `{"code":400,"message":"no checks provided"}`,
+		},
+		{
+			name:   "batch too large",
+			body:   `{"checks":[{"id":"a"},{"id":"b"},{"id":"c"}]}`,
+			status: http.StatusRequestEntityTooLarge,
+			out:    `{"code":413,"message":"batch size 3 exceeds maximum of 2"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Post("/check/batch", NewBatchCheckerHandler(Config{Checker: NewFake(true), MaxBatchSize: 2}))
+
+			req := httptest.NewRequest(http.MethodPost, "/check/batch", strings.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, tt.status, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.out, string(body))
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuthZEN evaluation semantics.
+// See https://openid.net/specs/authorization-api-1_0.html
+const (
+	AuthZENExecuteAll          = "execute_all"
+	AuthZENDenyOnFirstDeny     = "deny_on_first_deny"
+	AuthZENPermitOnFirstPermit = "permit_on_first_permit"
+)
+
+// AuthZENNamespaceSeparator is the separator of the type and the id of subjects and resources.
+const AuthZENNamespaceSeparator = ":"
+
+// AuthZENSubject is the subject of an AuthZEN evaluation.
+type AuthZENSubject struct {
+	// Type is the type of the subject.
+	Type string `json:"type"`
+	// ID is the unique identifier of the subject.
+	ID string `json:"id"`
+	// Properties are additional properties of the subject.
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// Principal returns the subject as principal in the form type:id.
+func (s *AuthZENSubject) Principal() AuthzPrincipal {
+	return AuthzPrincipal(authZENEntity(s.Type, s.ID))
+}
+
+// AuthZENResource is the resource of an AuthZEN evaluation.
+type AuthZENResource struct {
+	// Type is the type of the resource.
+	Type string `json:"type"`
+	// ID is the unique identifier of the resource.
+	ID string `json:"id"`
+	// Properties are additional properties of the resource.
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// Object returns the resource as object in the form type:id.
+func (r *AuthZENResource) Object() AuthzObject {
+	return AuthzObject(authZENEntity(r.Type, r.ID))
+}
+
+// AuthZENAction is the action of an AuthZEN evaluation.
+type AuthZENAction struct {
+	// Name is the name of the action.
+	Name string `json:"name"`
+	// Properties are additional properties of the action.
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// Action returns the name of the action.
+func (a *AuthZENAction) Action() AuthzAction {
+	return AuthzAction(a.Name)
+}
+
+// AuthZENEvaluationRequest is the request of the AuthZEN access evaluation API.
+type AuthZENEvaluationRequest struct {
+	Subject  *AuthZENSubject        `json:"subject,omitempty"`
+	Resource *AuthZENResource       `json:"resource,omitempty"`
+	Action   *AuthZENAction         `json:"action,omitempty"`
+	Context  map[string]interface{} `json:"context,omitempty"`
+}
+
+// AuthZENEvaluationResponse is the response of the AuthZEN access evaluation API.
+type AuthZENEvaluationResponse struct {
+	Decision bool                   `json:"decision"`
+	Context  map[string]interface{} `json:"context,omitempty"`
+}
+
+// AuthZENEvaluationsOptions are the options of the AuthZEN access evaluations API.
+type AuthZENEvaluationsOptions struct {
+	// EvaluationsSemantic is the semantic of the evaluations.
+	EvaluationsSemantic string `json:"evaluations_semantic,omitempty"`
+}
+
+// AuthZENEvaluationsRequest is the request of the AuthZEN access evaluations API.
+// The subject, resource, action and context are the defaults for every evaluation.
+type AuthZENEvaluationsRequest struct {
+	AuthZENEvaluationRequest
+
+	Evaluations []AuthZENEvaluationRequest `json:"evaluations,omitempty"`
+	Options     *AuthZENEvaluationsOptions `json:"options,omitempty"`
+}
+
+// AuthZENEvaluationsResponse is the response of the AuthZEN access evaluations API.
+type AuthZENEvaluationsResponse struct {
+	Evaluations []AuthZENEvaluationResponse `json:"evaluations"`
+}
+
+// AuthZENRoutes registers the AuthZEN access evaluation endpoints on the router.
+func AuthZENRoutes(r fiber.Router, config ...Config) {
+	r.Post("/access/v1/evaluation", NewAuthZENEvaluationHandler(config...))
+	r.Post("/access/v1/evaluations", NewAuthZENEvaluationsHandler(config...))
+}
+
+// NewAuthZENEvaluationHandler returns a new fiber.Handler that implements the AuthZEN access evaluation API.
+// Errors are reported with the JSONErrorHandler unless an ErrorHandler is configured.
+func NewAuthZENEvaluationHandler(config ...Config) fiber.Handler {
+	cfg := jsonConfigDefault(config...)
+
+	return func(c *fiber.Ctx) error {
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		payload := AuthZENEvaluationRequest{}
+
+		if err := c.BodyParser(&payload); err != nil {
+			return cfg.ErrorHandler(c, fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("error parsing body: %w", err).Error()))
+		}
+
+		if err := payload.validate(); err != nil {
+			return cfg.ErrorHandler(c, err)
+		}
+
+		res, err := authZENEvaluate(c.Context(), cfg.Checker, &payload)
+		if err != nil {
+			return cfg.ErrorHandler(c, err)
+		}
+
+		return c.JSON(res)
+	}
+}
+
+// NewAuthZENEvaluationsHandler returns a new fiber.Handler that implements the AuthZEN access evaluations API.
+// Errors are reported with the JSONErrorHandler unless an ErrorHandler is configured.
+func NewAuthZENEvaluationsHandler(config ...Config) fiber.Handler {
+	cfg := jsonConfigDefault(config...)
+
+	return func(c *fiber.Ctx) error {
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		payload := AuthZENEvaluationsRequest{}
+
+		if err := c.BodyParser(&payload); err != nil {
+			return cfg.ErrorHandler(c, fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("error parsing body: %w", err).Error()))
+		}
+
+		// Without evaluations this behaves like the access evaluation API.
+		if len(payload.Evaluations) == 0 {
+			if err := payload.validate(); err != nil {
+				return cfg.ErrorHandler(c, err)
+			}
+
+			res, err := authZENEvaluate(c.Context(), cfg.Checker, &payload.AuthZENEvaluationRequest)
+			if err != nil {
+				return cfg.ErrorHandler(c, err)
+			}
+
+			return c.JSON(res)
+		}
+
+		if len(payload.Evaluations) > cfg.MaxBatchSize {
+			return cfg.ErrorHandler(c, fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("batch size %d exceeds maximum of %d", len(payload.Evaluations), cfg.MaxBatchSize)))
+		}
+
+		evaluations := make([]AuthZENEvaluationRequest, len(payload.Evaluations))
+		for i, e := range payload.Evaluations {
+			evaluations[i] = payload.merge(e)
+
+			if err := evaluations[i].validate(); err != nil {
+				return cfg.ErrorHandler(c, err)
+			}
+		}
+
+		semantic := AuthZENExecuteAll
+		if payload.Options != nil && payload.Options.EvaluationsSemantic != "" {
+			semantic = payload.Options.EvaluationsSemantic
+		}
+
+		var res AuthZENEvaluationsResponse
+		var err error
+
+		switch semantic {
+		case AuthZENExecuteAll:
+			res, err = authZENExecuteAll(c.Context(), cfg.Checker, evaluations)
+		case AuthZENDenyOnFirstDeny:
+			res, err = authZENSequential(c.Context(), cfg.Checker, evaluations, func(decision bool) bool { return !decision })
+		case AuthZENPermitOnFirstPermit:
+			res, err = authZENSequential(c.Context(), cfg.Checker, evaluations, func(decision bool) bool { return decision })
+		default:
+			err = fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("unsupported evaluations semantic %q", semantic))
+		}
+
+		if err != nil {
+			return cfg.ErrorHandler(c, err)
+		}
+
+		return c.JSON(res)
+	}
+}
+
+func authZENEntity(typ, id string) string {
+	if typ == "" {
+		return id
+	}
+
+	return typ + AuthZENNamespaceSeparator + id
+}
+
+func (r *AuthZENEvaluationRequest) validate() error {
+	if r.Subject == nil || r.Subject.ID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "subject is required")
+	}
+
+	if r.Resource == nil || r.Resource.ID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "resource is required")
+	}
+
+	if r.Action == nil || r.Action.Name == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "action is required")
+	}
+
+	return nil
+}
+
+func (r *AuthZENEvaluationsRequest) merge(e AuthZENEvaluationRequest) AuthZENEvaluationRequest {
+	if e.Subject == nil {
+		e.Subject = r.Subject
+	}
+
+	if e.Resource == nil {
+		e.Resource = r.Resource
+	}
+
+	if e.Action == nil {
+		e.Action = r.Action
+	}
+
+	if e.Context == nil {
+		e.Context = r.Context
+	}
+
+	return e
+}
+
+// attributes returns the properties and context of the request, empty ones are omitted.
+func (r *AuthZENEvaluationRequest) attributes() Attributes {
+	attrs := Attributes{}
+
+	for k, v := range map[string]map[string]interface{}{
+		"subject":  r.Subject.Properties,
+		"resource": r.Resource.Properties,
+		"action":   r.Action.Properties,
+		"context":  r.Context,
+	} {
+		if len(v) > 0 {
+			attrs[k] = v
+		}
+	}
+
+	return attrs
+}
+
+func authZENResponse(decision Decision) AuthZENEvaluationResponse {
+	res := AuthZENEvaluationResponse{Decision: decision.Allowed}
+
+	if decision.Reason != "" {
+		res.Context = map[string]interface{}{"reason": decision.Reason}
+	}
+
+	return res
+}
+
+func authZENEvaluate(ctx context.Context, checker AuthzChecker, r *AuthZENEvaluationRequest) (AuthZENEvaluationResponse, error) {
+	decision, err := Decide(WithAttributes(ctx, r.attributes()), checker, r.Subject.Principal(), r.Resource.Object(), r.Action.Action())
+	if err != nil {
+		return AuthZENEvaluationResponse{}, err
+	}
+
+	return authZENResponse(decision), nil
+}
+
+func authZENExecuteAll(ctx context.Context, checker AuthzChecker, evaluations []AuthZENEvaluationRequest) (AuthZENEvaluationsResponse, error) {
+	// Evaluations with request attributes need their own context,
+	// only plain evaluations can be sent as a batch.
+	for _, e := range evaluations {
+		if len(e.Context) > 0 || len(e.Subject.Properties) > 0 || len(e.Resource.Properties) > 0 || len(e.Action.Properties) > 0 {
+			return authZENSequential(ctx, checker, evaluations, func(bool) bool { return false })
+		}
+	}
+
+	checks := make([]AuthzParams, len(evaluations))
+	for i, e := range evaluations {
+		checks[i] = AuthzParams{Principal: e.Subject.Principal(), Object: e.Resource.Object(), Action: e.Action.Action()}
+	}
+
+	decisions, err := BatchDecide(ctx, checker, checks)
+	if err != nil {
+		return AuthZENEvaluationsResponse{}, err
+	}
+
+	res := AuthZENEvaluationsResponse{Evaluations: make([]AuthZENEvaluationResponse, len(decisions))}
+	for i, decision := range decisions {
+		res.Evaluations[i] = authZENResponse(decision)
+	}
+
+	return res, nil
+}
+
+// authZENSequential evaluates in order and stops after the first decision for which stop returns true.
+func authZENSequential(ctx context.Context, checker AuthzChecker, evaluations []AuthZENEvaluationRequest, stop func(bool) bool) (AuthZENEvaluationsResponse, error) {
+	res := AuthZENEvaluationsResponse{Evaluations: make([]AuthZENEvaluationResponse, 0, len(evaluations))}
+
+	for i := range evaluations {
+		r, err := authZENEvaluate(ctx, checker, &evaluations[i])
+		if err != nil {
+			return AuthZENEvaluationsResponse{}, err
+		}
+
+		res.Evaluations = append(res.Evaluations, r)
+
+		if stop(r.Decision) {
+			break
+		}
+	}
+
+	return res, nil
+}
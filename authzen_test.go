@@ -0,0 +1,106 @@
+package authz
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+type resourceChecker struct{}
+
+func (r *resourceChecker) Allowed(ctx context.Context, principal AuthzPrincipal, object AuthzObject, action AuthzAction) (bool, error) {
+	return principal == "user:alice" && object == "account:123" && action == "can_read", nil
+}
+
+func TestAuthZEN(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		out    string
+	}{
+		{
+			name:   "evaluation allowed",
+			path:   "/access/v1/evaluation",
+			body:   `{"subject":{"type":"user","id":"alice"},"resource":{"type":"account","id":"123"},"action":{"name":"can_read"}}`,
+			status: http.StatusOK,
+			out:    `{"decision":true}`,
+		},
+		{
+			name:   "evaluation denied",
+			path:   "/access/v1/evaluation",
+			body:   `{"subject":{"type":"user","id":"bob"},"resource":{"type":"account","id":"123"},"action":{"name":"can_read"}}`,
+			status: http.StatusOK,
+			out:    `{"decision":false}`,
+		},
+		{
+			name:   "evaluation missing subject",
+			path:   "/access/v1/evaluation",
+			body:   `{"resource":{"type":"account","id":"123"},"action":{"name":"can_read"}}`,
+			status: http.StatusBadRequest,
+			out:    `{"code":400,"message":"subject is required"}`,
+		},
+		{
+			name:   "evaluations with defaults",
+			path:   "/access/v1/evaluations",
+			body:   `{"subject":{"type":"user","id":"alice"},"action":{"name":"can_read"},"evaluations":[{"resource":{"type":"account","id":"123"}},{"resource":{"type":"account","id":"456"}}]}`,
+			status: http.StatusOK,
+			out:    `{"evaluations":[{"decision":true},{"decision":false}]}`,
+		},
+		{
+			name:   "evaluations permit on first permit",
+			path:   "/access/v1/evaluations",
+			body:   `{"subject":{"type":"user","id":"alice"},"action":{"name":"can_read"},"evaluations":[{"resource":{"type":"account","id":"123"}},{"resource":{"type":"account","id":"456"}}],"options":{"evaluations_semantic":"permit_on_first_permit"}}`,
+			status: http.StatusOK,
+			out:    `{"evaluations":[{"decision":true}]}`,
+		},
+		{
+			name:   "evaluations without evaluations",
+			path:   "/access/v1/evaluations",
+			body:   `{"subject":{"type":"user","id":"alice"},"resource":{"type":"account","id":"123"},"action":{"name":"can_read"}}`,
+			status: http.StatusOK,
+			out:    `{"decision":true}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			AuthZENRoutes(app, Config{Checker: &resourceChecker{}})
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, tt.status, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.out, string(body))
+		})
+	}
+}
+
+func TestAuthZENAttributes(t *testing.T) {
+	t.Parallel()
+
+	r := &AuthZENEvaluationRequest{
+		Subject:  &AuthZENSubject{Type: "user", ID: "alice"},
+		Resource: &AuthZENResource{Type: "account", ID: "123"},
+		Action:   &AuthZENAction{Name: "can_read"},
+	}
+	require.Empty(t, r.attributes())
+
+	r.Context = map[string]interface{}{"ip": "10.0.0.1"}
+	require.Equal(t, Attributes{"context": map[string]interface{}{"ip": "10.0.0.1"}}, r.attributes())
+}
@@ -0,0 +1,47 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/zeiss/fiber-authz/internal/batch"
+)
+
+// DefaultBatchConcurrency is the default number of concurrent checks of the fallback batch checker.
+const DefaultBatchConcurrency = 10
+
+// BatchChecker is the interface that wraps the BatchDecide method.
+type BatchChecker interface {
+	// BatchDecide returns a decision for every check in the order of the checks.
+	BatchDecide(context.Context, []AuthzParams) ([]Decision, error)
+}
+
+// BatchDecide returns a decision for every check in the order of the checks.
+// If the checker does not implement the BatchChecker interface,
+// the checks are fanned out with the DefaultBatchConcurrency.
+func BatchDecide(ctx context.Context, checker AuthzChecker, checks []AuthzParams) ([]Decision, error) {
+	if bc, ok := checker.(BatchChecker); ok {
+		return bc.BatchDecide(ctx, checks)
+	}
+
+	return NewBatch(checker, DefaultBatchConcurrency).BatchDecide(ctx, checks)
+}
+
+var _ BatchChecker = (*Batch)(nil)
+
+// Batch is a batch checker that fans out the checks to a checker.
+type Batch struct {
+	checker     AuthzChecker
+	concurrency int
+}
+
+// NewBatch returns a new batch checker with bounded concurrency.
+func NewBatch(checker AuthzChecker, concurrency int) *Batch {
+	return &Batch{checker: checker, concurrency: concurrency}
+}
+
+// BatchDecide returns a decision for every check in the order of the checks.
+func (b *Batch) BatchDecide(ctx context.Context, checks []AuthzParams) ([]Decision, error) {
+	return batch.Map(ctx, checks, b.concurrency, func(ctx context.Context, check AuthzParams) (Decision, error) {
+		return Decide(ctx, b.checker, check.Principal, check.Object, check.Action)
+	})
+}
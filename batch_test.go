@@ -0,0 +1,102 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openfga/go-sdk/client"
+	"github.com/stretchr/testify/require"
+)
+
+type objectChecker struct {
+	inflight atomic.Int64
+	max      atomic.Int64
+}
+
+func (o *objectChecker) Allowed(_ context.Context, _ AuthzPrincipal, object AuthzObject, _ AuthzAction) (bool, error) {
+	n := o.inflight.Add(1)
+	defer o.inflight.Add(-1)
+
+	for {
+		m := o.max.Load()
+		if n <= m || o.max.CompareAndSwap(m, n) {
+			break
+		}
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	if object == "error" {
+		return false, errors.New("error")
+	}
+
+	return object == "allowed", nil
+}
+
+func TestBatchDecide(t *testing.T) {
+	t.Parallel()
+
+	checker := &objectChecker{}
+
+	checks := make([]AuthzParams, 0, 20)
+	for i := range 20 {
+		object := AuthzObject("denied")
+		if i%2 == 0 {
+			object = "allowed"
+		}
+
+		checks = append(checks, AuthzParams{Principal: "principal", Object: object, Action: "action"})
+	}
+
+	decisions, err := NewBatch(checker, 3).BatchDecide(context.TODO(), checks)
+	require.NoError(t, err)
+	require.Len(t, decisions, len(checks))
+
+	for i, decision := range decisions {
+		require.Equal(t, i%2 == 0, decision.Allowed)
+	}
+
+	require.LessOrEqual(t, checker.max.Load(), int64(3))
+}
+
+func TestBatchDecideError(t *testing.T) {
+	t.Parallel()
+
+	checks := []AuthzParams{
+		{Principal: "principal", Object: "allowed", Action: "action"},
+		{Principal: "principal", Object: "error", Action: "action"},
+	}
+
+	_, err := BatchDecide(context.TODO(), &objectChecker{}, checks)
+	require.Error(t, err)
+}
+
+func TestFGABatchDecideItemError(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"result":{"0":{"allowed":true},"1":{"allowed":false,"error":{"message":"type not found"}}}}`))
+	}))
+	defer srv.Close()
+
+	fgaClient, err := client.NewSdkClient(&client.ClientConfiguration{ApiUrl: srv.URL, StoreId: "01ARZ3NDEKTSV4RRFFQ69G5FAV"})
+	require.NoError(t, err)
+
+	checks := []AuthzParams{
+		{Principal: "user:alice", Object: "viewer", Action: "doc:1"},
+		{Principal: "user:alice", Object: "viewer", Action: "unknown:1"},
+	}
+
+	decisions, err := NewFGA(fgaClient).BatchDecide(context.Background(), checks)
+	require.NoError(t, err)
+	require.Len(t, decisions, 2)
+	require.True(t, decisions[0].Allowed)
+	require.False(t, decisions[1].Allowed)
+	require.Equal(t, "check failed: type not found", decisions[1].Reason)
+}
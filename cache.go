@@ -0,0 +1,204 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/zeiss/fiber-authz/internal/cache"
+	"github.com/zeiss/fiber-authz/openfga"
+)
+
+var (
+	_ AuthzChecker        = (*Cache)(nil)
+	_ DecisionChecker     = (*Cache)(nil)
+	_ openfga.Invalidator = (*Cache)(nil)
+)
+
+// CacheOpts are the options for the decision cache.
+type CacheOpts struct {
+	// PositiveTTL is the time an allowed decision is cached.
+	PositiveTTL time.Duration
+	// NegativeTTL is the time a denied decision is cached.
+	NegativeTTL time.Duration
+	// MaxEntries is the maximum number of cached decisions, zero means no limit.
+	MaxEntries int
+	// Clock returns the current time.
+	Clock func() time.Time
+}
+
+// Configure sets the configuration for the cache.
+func (o *CacheOpts) Configure(opts ...CacheOpt) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// CacheOpt is a function that sets an option on the cache.
+type CacheOpt func(*CacheOpts)
+
+// DefaultCacheOpts returns the default cache options.
+func DefaultCacheOpts() CacheOpts {
+	return CacheOpts{
+		PositiveTTL: 10 * time.Second,
+		NegativeTTL: 10 * time.Second,
+		MaxEntries:  10000,
+		Clock:       time.Now,
+	}
+}
+
+// WithCacheTTL sets the time allowed and denied decisions are cached.
+func WithCacheTTL(ttl time.Duration) CacheOpt {
+	return func(o *CacheOpts) {
+		o.PositiveTTL = ttl
+		o.NegativeTTL = ttl
+	}
+}
+
+// WithCachePositiveTTL sets the time allowed decisions are cached.
+func WithCachePositiveTTL(ttl time.Duration) CacheOpt {
+	return func(o *CacheOpts) {
+		o.PositiveTTL = ttl
+	}
+}
+
+// WithCacheNegativeTTL sets the time denied decisions are cached.
+func WithCacheNegativeTTL(ttl time.Duration) CacheOpt {
+	return func(o *CacheOpts) {
+		o.NegativeTTL = ttl
+	}
+}
+
+// WithCacheMaxEntries sets the maximum number of cached decisions.
+func WithCacheMaxEntries(n int) CacheOpt {
+	return func(o *CacheOpts) {
+		o.MaxEntries = n
+	}
+}
+
+// WithCacheClock sets the clock of the cache.
+func WithCacheClock(clock func() time.Time) CacheOpt {
+	return func(o *CacheOpts) {
+		o.Clock = clock
+	}
+}
+
+// Cache is an authz checker that caches the decisions of another checker.
+type Cache struct {
+	checker AuthzChecker
+	cache   *cache.Cache[Decision]
+	opts    CacheOpts
+}
+
+// NewCache returns a new caching authz checker.
+func NewCache(checker AuthzChecker, opts ...CacheOpt) *Cache {
+	options := DefaultCacheOpts()
+	options.Configure(opts...)
+
+	return &Cache{
+		checker: checker,
+		cache:   cache.New[Decision](options.MaxEntries, options.Clock),
+		opts:    options,
+	}
+}
+
+// Allowed returns true if the principal is allowed to perform the action on the object.
+func (c *Cache) Allowed(ctx context.Context, principal AuthzPrincipal, object AuthzObject, action AuthzAction) (bool, error) {
+	decision, err := c.Decide(ctx, principal, object, action)
+	if err != nil {
+		return false, err
+	}
+
+	return decision.Allowed, nil
+}
+
+// Decide returns the cached decision or asks the wrapped checker.
+// Decisions are cached per attributes of the request and, if the checker implements
+// openfga.StoreScoper, per store and authorization model. Decisions that depend on
+// contextual tuples, condition context or higher consistency are not cached.
+func (c *Cache) Decide(ctx context.Context, principal AuthzPrincipal, object AuthzObject, action AuthzAction) (Decision, error) {
+	attrs, err := attributesKey(ctx)
+	if err != nil || isContextual(ctx) {
+		return Decide(ctx, c.checker, principal, object, action)
+	}
+
+	var store string
+
+	if s, ok := c.checker.(openfga.StoreScoper); ok {
+		scope, err := s.StoreScope(ctx)
+		if err != nil {
+			return Decide(ctx, c.checker, principal, object, action)
+		}
+
+		store = scope
+	}
+
+	key := cache.Key{Principal: principal.String(), Object: object.String(), Action: action.String(), Context: attrs, Store: store}
+
+	return c.cache.Do(ctx, key, func() (Decision, time.Duration, error) {
+		decision, err := Decide(ctx, c.checker, principal, object, action)
+		if err != nil {
+			return decision, 0, err
+		}
+
+		if decision.Allowed {
+			return decision, c.opts.PositiveTTL, nil
+		}
+
+		return decision, c.opts.NegativeTTL, nil
+	})
+}
+
+// InvalidatePrincipal removes all cached decisions of the principal.
+func (c *Cache) InvalidatePrincipal(principal AuthzPrincipal) {
+	c.cache.DeleteFunc(func(k cache.Key) bool {
+		return k.Principal == principal.String()
+	})
+}
+
+// InvalidateObject removes all cached decisions of the object.
+func (c *Cache) InvalidateObject(object AuthzObject) {
+	c.cache.DeleteFunc(func(k cache.Key) bool {
+		return k.Object == object.String()
+	})
+}
+
+// Invalidate removes all cached decisions of the users and objects of the tuples,
+// e.g. after the tuples have been written with openfga.NewGrantHandler.
+func (c *Cache) Invalidate(tuples ...openfga.Tuple) {
+	for _, t := range tuples {
+		c.InvalidatePrincipal(AuthzPrincipal(openfga.EntityString(t.User)))
+		c.InvalidateObject(AuthzObject(openfga.EntityString(t.Object)))
+	}
+}
+
+// Flush removes all cached decisions.
+func (c *Cache) Flush() {
+	c.cache.Flush()
+}
+
+// attributesKey returns the attributes of the request as a cache key,
+// the keys of maps are sorted by encoding/json.
+func attributesKey(ctx context.Context) (string, error) {
+	attrs := GetAttributes(ctx)
+	if len(attrs) == 0 {
+		return "", nil
+	}
+
+	b, err := json.Marshal(attrs)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func isContextual(ctx context.Context) bool {
+	if len(openfga.GetContextualTuples(ctx)) > 0 || len(openfga.GetConditionContext(ctx)) > 0 {
+		return true
+	}
+
+	consistency, ok := openfga.GetConsistency(ctx)
+
+	return ok && consistency == openfga.ConsistencyHigher
+}
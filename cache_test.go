@@ -0,0 +1,301 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openfga/go-sdk/client"
+	"github.com/stretchr/testify/require"
+	"github.com/zeiss/fiber-authz/openfga"
+)
+
+type countingChecker struct {
+	calls   atomic.Int64
+	allowed bool
+	delay   time.Duration
+}
+
+func (c *countingChecker) Allowed(_ context.Context, _ AuthzPrincipal, _ AuthzObject, _ AuthzAction) (bool, error) {
+	c.calls.Add(1)
+	time.Sleep(c.delay)
+
+	return c.allowed, nil
+}
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+}
+
+func TestCacheTTL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		allowed bool
+		opts    []CacheOpt
+		advance time.Duration
+		calls   int64
+	}{
+		{
+			name:    "positive hit",
+			allowed: true,
+			opts:    []CacheOpt{WithCachePositiveTTL(time.Minute)},
+			advance: 30 * time.Second,
+			calls:   1,
+		},
+		{
+			name:    "positive expired",
+			allowed: true,
+			opts:    []CacheOpt{WithCachePositiveTTL(time.Minute)},
+			advance: time.Minute,
+			calls:   2,
+		},
+		{
+			name:    "negative expired",
+			allowed: false,
+			opts:    []CacheOpt{WithCachePositiveTTL(time.Hour), WithCacheNegativeTTL(time.Second)},
+			advance: 2 * time.Second,
+			calls:   2,
+		},
+		{
+			name:    "disabled",
+			allowed: true,
+			opts:    []CacheOpt{WithCacheTTL(0)},
+			calls:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Now()}
+			checker := &countingChecker{allowed: tt.allowed}
+
+			c := NewCache(checker, append(tt.opts, WithCacheClock(clock.Now))...)
+
+			allowed, err := c.Allowed(context.TODO(), "principal", "object", "action")
+			require.NoError(t, err)
+			require.Equal(t, tt.allowed, allowed)
+
+			clock.Advance(tt.advance)
+
+			allowed, err = c.Allowed(context.TODO(), "principal", "object", "action")
+			require.NoError(t, err)
+			require.Equal(t, tt.allowed, allowed)
+			require.Equal(t, tt.calls, checker.calls.Load())
+		})
+	}
+}
+
+func TestCacheMaxEntries(t *testing.T) {
+	t.Parallel()
+
+	checker := &countingChecker{allowed: true}
+	c := NewCache(checker, WithCacheMaxEntries(2))
+
+	for _, object := range []AuthzObject{"a", "b", "a", "c", "a", "b"} {
+		_, err := c.Allowed(context.TODO(), "principal", object, "action")
+		require.NoError(t, err)
+	}
+
+	// "b" is evicted by "c" because "a" was used more recently.
+	require.Equal(t, int64(4), checker.calls.Load())
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	t.Parallel()
+
+	checker := &countingChecker{allowed: true}
+	c := NewCache(checker)
+
+	_, err := c.Allowed(context.TODO(), "alice", "a", "action")
+	require.NoError(t, err)
+	_, err = c.Allowed(context.TODO(), "bob", "b", "action")
+	require.NoError(t, err)
+
+	c.InvalidatePrincipal("alice")
+	c.InvalidateObject("b")
+
+	_, err = c.Allowed(context.TODO(), "alice", "a", "action")
+	require.NoError(t, err)
+	_, err = c.Allowed(context.TODO(), "bob", "b", "action")
+	require.NoError(t, err)
+	require.Equal(t, int64(4), checker.calls.Load())
+
+	c.Flush()
+
+	_, err = c.Allowed(context.TODO(), "alice", "a", "action")
+	require.NoError(t, err)
+	require.Equal(t, int64(5), checker.calls.Load())
+}
+
+func TestCacheSingleflight(t *testing.T) {
+	t.Parallel()
+
+	checker := &countingChecker{allowed: true, delay: 50 * time.Millisecond}
+	c := NewCache(checker)
+
+	var wg sync.WaitGroup
+	var allowedCount atomic.Int64
+
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			allowed, err := c.Allowed(context.TODO(), "principal", "object", "action")
+			if err == nil && allowed {
+				allowedCount.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int64(10), allowedCount.Load())
+	require.Equal(t, int64(1), checker.calls.Load())
+}
+
+func TestCacheContextual(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{
+			name: "contextual tuples",
+			ctx:  openfga.WithContextualTuples(context.Background(), openfga.NewTuple("user:alice", "member", "team:a")),
+		},
+		{
+			name: "condition context",
+			ctx:  openfga.WithConditionContext(context.Background(), map[string]interface{}{"ip": "10.0.0.1"}),
+		},
+		{
+			name: "higher consistency",
+			ctx:  openfga.WithConsistencyContext(context.Background(), openfga.ConsistencyHigher),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			checker := &countingChecker{allowed: true}
+			c := NewCache(checker)
+
+			for range 2 {
+				_, err := c.Allowed(tt.ctx, "principal", "object", "action")
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, int64(2), checker.calls.Load())
+		})
+	}
+}
+
+func TestCacheAttributes(t *testing.T) {
+	t.Parallel()
+
+	checker := &countingChecker{allowed: true}
+	c := NewCache(checker)
+
+	for _, ip := range []string{"10.0.0.1", "10.0.0.1", "10.0.0.2"} {
+		ctx := WithAttributes(context.Background(), Attributes{"ip": ip})
+
+		_, err := c.Allowed(ctx, "principal", "object", "action")
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, int64(2), checker.calls.Load())
+
+	c.InvalidatePrincipal("principal")
+
+	_, err := c.Allowed(WithAttributes(context.Background(), Attributes{"ip": "10.0.0.1"}), "principal", "object", "action")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), checker.calls.Load())
+}
+
+func TestCacheInvalidateTuples(t *testing.T) {
+	t.Parallel()
+
+	checker := &countingChecker{allowed: true}
+	c := NewCache(checker)
+
+	_, err := c.Allowed(context.TODO(), "user:alice", "team:a", "member")
+	require.NoError(t, err)
+	_, err = c.Allowed(context.TODO(), "user:bob", "team:b", "member")
+	require.NoError(t, err)
+
+	c.Invalidate(openfga.NewTuple("user:alice", "member", "team:b"))
+
+	_, err = c.Allowed(context.TODO(), "user:alice", "team:a", "member")
+	require.NoError(t, err)
+	_, err = c.Allowed(context.TODO(), "user:bob", "team:b", "member")
+	require.NoError(t, err)
+	require.Equal(t, int64(4), checker.calls.Load())
+}
+
+func TestCacheTenants(t *testing.T) {
+	t.Parallel()
+
+	const (
+		storeA = "01ARZ3NDEKTSV4RRFFQ69G5FAX"
+		storeB = "01ARZ3NDEKTSV4RRFFQ69G5FAY"
+		model  = "01ARZ3NDEKTSV4RRFFQ69G5FAZ"
+	)
+
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]bool{"allowed": r.URL.Path == "/stores/"+storeA+"/check"})
+	}))
+	defer srv.Close()
+
+	fgaClient, err := client.NewSdkClient(&client.ClientConfiguration{ApiUrl: srv.URL, StoreId: storeA})
+	require.NoError(t, err)
+
+	checker := NewFGA(fgaClient, openfga.WithStoreResolver(func(ctx context.Context) (openfga.Store, bool) {
+		store, ok := ctx.Value("tenant").(string)
+
+		return openfga.Store{ID: store, ModelID: model}, ok
+	}))
+	cache := NewCache(checker)
+
+	tenantA := context.WithValue(context.Background(), "tenant", storeA) //nolint:staticcheck
+	tenantB := context.WithValue(context.Background(), "tenant", storeB) //nolint:staticcheck
+
+	for range 2 {
+		allowed, err := cache.Allowed(tenantA, "user:alice", "viewer", "doc:1")
+		require.NoError(t, err)
+		require.True(t, allowed)
+
+		allowed, err = cache.Allowed(tenantB, "user:alice", "viewer", "doc:1")
+		require.NoError(t, err)
+		require.False(t, allowed)
+	}
+
+	require.Equal(t, int32(2), calls.Load())
+}
@@ -0,0 +1,172 @@
+package casbin
+
+import (
+	"fmt"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/zeiss/fiber-authz/tbrac"
+	"gorm.io/gorm"
+)
+
+var (
+	_ persist.Adapter      = (*Adapter)(nil)
+	_ persist.BatchAdapter = (*Adapter)(nil)
+)
+
+// maxRuleValues is the number of values a rule can hold.
+const maxRuleValues = 6
+
+// Adapter is a Casbin policy adapter that stores the rules
+// in the database that is managed by tbrac.RunMigrations.
+type Adapter struct {
+	db *gorm.DB
+}
+
+// NewAdapter returns a new Casbin policy adapter.
+func NewAdapter(db *gorm.DB) *Adapter {
+	return &Adapter{db: db}
+}
+
+// LoadPolicy loads all policy rules from the database.
+func (a *Adapter) LoadPolicy(m model.Model) error {
+	var rules []tbrac.CasbinRule
+
+	if err := a.db.Order("id").Find(&rules).Error; err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		if err := persist.LoadPolicyArray(ruleToArray(rule), m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SavePolicy replaces all policy rules in the database.
+func (a *Adapter) SavePolicy(m model.Model) error {
+	rules := []tbrac.CasbinRule{}
+
+	for _, sec := range []string{"p", "g"} {
+		for ptype, ast := range m[sec] {
+			for _, policy := range ast.Policy {
+				rule, err := newRule(ptype, policy)
+				if err != nil {
+					return err
+				}
+
+				rules = append(rules, rule)
+			}
+		}
+	}
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&tbrac.CasbinRule{}).Error; err != nil {
+			return err
+		}
+
+		if len(rules) == 0 {
+			return nil
+		}
+
+		return tx.Create(&rules).Error
+	})
+}
+
+// AddPolicy adds a policy rule to the database.
+func (a *Adapter) AddPolicy(_ string, ptype string, rule []string) error {
+	return a.AddPolicies("", ptype, [][]string{rule})
+}
+
+// AddPolicies adds policy rules to the database.
+func (a *Adapter) AddPolicies(_ string, ptype string, rules [][]string) error {
+	rs := make([]tbrac.CasbinRule, 0, len(rules))
+
+	for _, rule := range rules {
+		r, err := newRule(ptype, rule)
+		if err != nil {
+			return err
+		}
+
+		rs = append(rs, r)
+	}
+
+	if len(rs) == 0 {
+		return nil
+	}
+
+	return a.db.Create(&rs).Error
+}
+
+// RemovePolicy removes a policy rule from the database.
+func (a *Adapter) RemovePolicy(_ string, ptype string, rule []string) error {
+	return a.RemovePolicies("", ptype, [][]string{rule})
+}
+
+// RemovePolicies removes policy rules from the database.
+func (a *Adapter) RemovePolicies(_ string, ptype string, rules [][]string) error {
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		for _, rule := range rules {
+			r, err := newRule(ptype, rule)
+			if err != nil {
+				return err
+			}
+
+			err = tx.Where(map[string]interface{}{
+				"ptype": r.Ptype, "v0": r.V0, "v1": r.V1, "v2": r.V2, "v3": r.V3, "v4": r.V4, "v5": r.V5,
+			}).Delete(&tbrac.CasbinRule{}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// RemoveFilteredPolicy removes the policy rules that match the filter from the database.
+func (a *Adapter) RemoveFilteredPolicy(_ string, ptype string, fieldIndex int, fieldValues ...string) error {
+	if fieldIndex < 0 || fieldIndex+len(fieldValues) > maxRuleValues {
+		return fmt.Errorf("casbin: invalid filter at index %d with %d values", fieldIndex, len(fieldValues))
+	}
+
+	tx := a.db.Where("ptype = ?", ptype)
+
+	for i, v := range fieldValues {
+		if v == "" {
+			continue
+		}
+
+		tx = tx.Where(fmt.Sprintf("v%d = ?", fieldIndex+i), v)
+	}
+
+	return tx.Delete(&tbrac.CasbinRule{}).Error
+}
+
+func newRule(ptype string, rule []string) (tbrac.CasbinRule, error) {
+	if len(rule) > maxRuleValues {
+		return tbrac.CasbinRule{}, fmt.Errorf("casbin: rule has %d values, maximum is %d", len(rule), maxRuleValues)
+	}
+
+	r := tbrac.CasbinRule{Ptype: ptype}
+	values := []*string{&r.V0, &r.V1, &r.V2, &r.V3, &r.V4, &r.V5}
+
+	for i, v := range rule {
+		*values[i] = v
+	}
+
+	return r, nil
+}
+
+func ruleToArray(r tbrac.CasbinRule) []string {
+	rule := []string{r.Ptype, r.V0, r.V1, r.V2, r.V3, r.V4, r.V5}
+
+	// Trim the empty trailing values.
+	for len(rule) > 1 && rule[len(rule)-1] == "" {
+		rule = rule[:len(rule)-1]
+	}
+
+	return rule
+}
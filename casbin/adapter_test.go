@@ -0,0 +1,117 @@
+package casbin_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	casbinv2 "github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	stringadapter "github.com/casbin/casbin/v2/persist/string-adapter"
+	"github.com/stretchr/testify/require"
+	"github.com/zeiss/fiber-authz/casbin"
+	"github.com/zeiss/fiber-authz/tbrac"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newAdapter(t *testing.T) *casbin.Adapter {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "casbin.db")), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&tbrac.CasbinRule{}))
+
+	return casbin.NewAdapter(db)
+}
+
+func newEnforcer(t *testing.T, adapter *casbin.Adapter) *casbinv2.Enforcer {
+	t.Helper()
+
+	m, err := model.NewModelFromString(rbacWithDomains)
+	require.NoError(t, err)
+
+	e, err := casbinv2.NewEnforcer(m, adapter)
+	require.NoError(t, err)
+
+	return e
+}
+
+func TestAdapterSavePolicy(t *testing.T) {
+	t.Parallel()
+
+	adapter := newAdapter(t)
+
+	m, err := model.NewModelFromString(rbacWithDomains)
+	require.NoError(t, err)
+
+	e, err := casbinv2.NewEnforcer(m, stringadapter.NewAdapter(policies))
+	require.NoError(t, err)
+
+	require.NoError(t, adapter.SavePolicy(e.GetModel()))
+	require.NoError(t, adapter.SavePolicy(e.GetModel()))
+
+	loaded := newEnforcer(t, adapter)
+
+	p, err := loaded.GetPolicy()
+	require.NoError(t, err)
+	require.ElementsMatch(t, [][]string{
+		{"admin", "zeiss", "workload", "write"},
+		{"viewer", "zeiss", "workload", "read"},
+	}, p)
+
+	g, err := loaded.GetGroupingPolicy()
+	require.NoError(t, err)
+	require.ElementsMatch(t, [][]string{
+		{"alice", "admin", "zeiss"},
+		{"bob", "viewer", "zeiss"},
+	}, g)
+
+	allowed, err := loaded.Enforce("alice", "zeiss", "workload", "write")
+	require.NoError(t, err)
+	require.True(t, allowed)
+}
+
+func TestAdapterAddRemovePolicy(t *testing.T) {
+	t.Parallel()
+
+	adapter := newAdapter(t)
+	e := newEnforcer(t, adapter)
+
+	_, err := e.AddPolicy("admin", "zeiss", "workload", "write")
+	require.NoError(t, err)
+	_, err = e.AddPolicies([][]string{{"viewer", "zeiss", "workload", "read"}, {"viewer", "zeiss", "workload", "list"}})
+	require.NoError(t, err)
+	_, err = e.AddGroupingPolicy("alice", "admin", "zeiss")
+	require.NoError(t, err)
+
+	allowed, err := newEnforcer(t, adapter).Enforce("alice", "zeiss", "workload", "write")
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	_, err = e.RemovePolicy("admin", "zeiss", "workload", "write")
+	require.NoError(t, err)
+
+	allowed, err = newEnforcer(t, adapter).Enforce("alice", "zeiss", "workload", "write")
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	_, err = e.RemoveFilteredPolicy(0, "viewer", "zeiss")
+	require.NoError(t, err)
+
+	p, err := newEnforcer(t, adapter).GetPolicy()
+	require.NoError(t, err)
+	require.Empty(t, p)
+
+	g, err := newEnforcer(t, adapter).GetGroupingPolicy()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"alice", "admin", "zeiss"}}, g)
+}
+
+func TestAdapterInvalidRule(t *testing.T) {
+	t.Parallel()
+
+	adapter := newAdapter(t)
+
+	require.Error(t, adapter.AddPolicy("p", "p", []string{"a", "b", "c", "d", "e", "f", "g"}))
+	require.Error(t, adapter.RemoveFilteredPolicy("p", "p", 5, "a", "b"))
+}
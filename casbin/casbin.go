@@ -0,0 +1,115 @@
+package casbin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	authz "github.com/zeiss/fiber-authz"
+)
+
+var (
+	_ authz.AuthzChecker    = (*Checker)(nil)
+	_ authz.DecisionChecker = (*Checker)(nil)
+)
+
+// Enforcer is the part of the Casbin enforcer that is used by the checker.
+// It is implemented by *casbin.Enforcer, *casbin.SyncedEnforcer and *casbin.CachedEnforcer.
+type Enforcer interface {
+	// EnforceEx decides whether a subject can access an object with the operation
+	// and returns the matched rule.
+	EnforceEx(rvals ...interface{}) (bool, []string, error)
+}
+
+// DomainResolver returns the domain of a check, e.g. the tenant for RBAC with domains.
+type DomainResolver func(ctx context.Context, principal authz.AuthzPrincipal, object authz.AuthzObject, action authz.AuthzAction) (string, error)
+
+// Opts are the options for the checker.
+type Opts struct {
+	// DomainResolver resolves the domain of a check.
+	// If nil, the enforcer is called with sub, obj, act.
+	DomainResolver DomainResolver
+}
+
+// Configure sets the configuration for the checker.
+func (o *Opts) Configure(opts ...Opt) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// Opt is a function that sets an option on the checker.
+type Opt func(*Opts)
+
+// DefaultOpts returns the default options.
+func DefaultOpts() Opts {
+	return Opts{}
+}
+
+// WithDomainResolver sets the domain resolver.
+// The enforcer is then called with sub, dom, obj, act.
+func WithDomainResolver(resolver DomainResolver) Opt {
+	return func(o *Opts) {
+		o.DomainResolver = resolver
+	}
+}
+
+// Checker is an authz checker that uses a Casbin enforcer.
+type Checker struct {
+	enforcer Enforcer
+	opts     Opts
+}
+
+// NewChecker returns a new Casbin authz checker.
+func NewChecker(enforcer Enforcer, opts ...Opt) *Checker {
+	options := DefaultOpts()
+	options.Configure(opts...)
+
+	return &Checker{enforcer: enforcer, opts: options}
+}
+
+// Allowed returns true if the principal is allowed to perform the action on the object.
+func (c *Checker) Allowed(ctx context.Context, principal authz.AuthzPrincipal, object authz.AuthzObject, action authz.AuthzAction) (bool, error) {
+	decision, err := c.Decide(ctx, principal, object, action)
+	if err != nil {
+		return false, err
+	}
+
+	return decision.Allowed, nil
+}
+
+// Decide enforces the request and returns the matched rule as policy.
+func (c *Checker) Decide(ctx context.Context, principal authz.AuthzPrincipal, object authz.AuthzObject, action authz.AuthzAction) (authz.Decision, error) {
+	start := time.Now()
+
+	rvals := []interface{}{principal.String(), object.String(), action.String()}
+
+	if c.opts.DomainResolver != nil {
+		domain, err := c.opts.DomainResolver(ctx, principal, object, action)
+		if err != nil {
+			return authz.Decision{}, err
+		}
+
+		rvals = []interface{}{principal.String(), domain, object.String(), action.String()}
+	}
+
+	allowed, explain, err := c.enforcer.EnforceEx(rvals...)
+	if err != nil {
+		return authz.Decision{}, err
+	}
+
+	policy := strings.Join(explain, ", ")
+
+	decision := authz.Deny("casbin", "no matching policy")
+	if allowed {
+		decision = authz.Allow("casbin", fmt.Sprintf("matched policy %s", policy))
+	} else if policy != "" {
+		decision.Reason = fmt.Sprintf("denied by policy %s", policy)
+	}
+
+	decision.Policy = policy
+	decision.Duration = time.Since(start)
+
+	return decision, nil
+}
@@ -0,0 +1,89 @@
+package casbin_test
+
+import (
+	"context"
+	"testing"
+
+	casbinv2 "github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	stringadapter "github.com/casbin/casbin/v2/persist/string-adapter"
+	"github.com/stretchr/testify/require"
+	authz "github.com/zeiss/fiber-authz"
+	"github.com/zeiss/fiber-authz/casbin"
+)
+
+const rbacWithDomains = `
+[request_definition]
+r = sub, dom, obj, act
+
+[policy_definition]
+p = sub, dom, obj, act
+
+[role_definition]
+g = _, _, _
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = g(r.sub, p.sub, r.dom) && r.dom == p.dom && r.obj == p.obj && r.act == p.act
+`
+
+const policies = `
+p, admin, zeiss, workload, write
+p, viewer, zeiss, workload, read
+g, alice, admin, zeiss
+g, bob, viewer, zeiss
+`
+
+func TestChecker(t *testing.T) {
+	t.Parallel()
+
+	m, err := model.NewModelFromString(rbacWithDomains)
+	require.NoError(t, err)
+
+	enforcer, err := casbinv2.NewEnforcer(m, stringadapter.NewAdapter(policies))
+	require.NoError(t, err)
+
+	checker := casbin.NewChecker(enforcer, casbin.WithDomainResolver(func(_ context.Context, _ authz.AuthzPrincipal, _ authz.AuthzObject, _ authz.AuthzAction) (string, error) {
+		return "zeiss", nil
+	}))
+
+	tests := []struct {
+		name      string
+		principal authz.AuthzPrincipal
+		action    authz.AuthzAction
+		allowed   bool
+		policy    string
+	}{
+		{
+			name:      "admin can write",
+			principal: "alice",
+			action:    "write",
+			allowed:   true,
+			policy:    "admin, zeiss, workload, write",
+		},
+		{
+			name:      "viewer can read",
+			principal: "bob",
+			action:    "read",
+			allowed:   true,
+			policy:    "viewer, zeiss, workload, read",
+		},
+		{
+			name:      "viewer can not write",
+			principal: "bob",
+			action:    "write",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := checker.Decide(context.Background(), tt.principal, "workload", tt.action)
+			require.NoError(t, err)
+			require.Equal(t, tt.allowed, decision.Allowed)
+			require.Equal(t, tt.policy, decision.Policy)
+			require.Equal(t, "casbin", decision.Checker)
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package cedar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cedar-policy/cedar-go"
+	xast "github.com/cedar-policy/cedar-go/x/exp/ast"
+	"github.com/cedar-policy/cedar-go/x/exp/schema"
+	"github.com/cedar-policy/cedar-go/x/exp/schema/validate"
+	authz "github.com/zeiss/fiber-authz"
+)
+
+// DefaultNamespaceSeparator is the separator of the type and the id,
+// the same as the openfga.DefaultNamespaceSeparator.
+const DefaultNamespaceSeparator = ":"
+
+// DefaultActionType is the entity type of actions without a type.
+const DefaultActionType = "Action"
+
+// ErrNoPolicies is returned when no policies are configured.
+var ErrNoPolicies = errors.New("cedar: no policies configured")
+
+var (
+	_ authz.AuthzChecker    = (*Checker)(nil)
+	_ authz.DecisionChecker = (*Checker)(nil)
+)
+
+// Opts are the options for the checker.
+type Opts struct {
+	// PolicySet is the set of policies that is evaluated.
+	PolicySet *cedar.PolicySet
+	// Entities are the entities that are used in the evaluation.
+	Entities cedar.EntityMap
+	// Schema is the schema the policies and entities are validated against.
+	Schema *schema.Schema
+	// ActionType is the entity type of actions without a type.
+	ActionType string
+	// Separator is the separator of the type and the id.
+	Separator string
+
+	err error
+}
+
+// Configure sets the configuration for the checker.
+func (o *Opts) Configure(opts ...Opt) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// Opt is a function that sets an option on the checker.
+type Opt func(*Opts)
+
+// DefaultOpts returns the default options.
+func DefaultOpts() Opts {
+	return Opts{
+		Entities:   cedar.EntityMap{},
+		ActionType: DefaultActionType,
+		Separator:  DefaultNamespaceSeparator,
+	}
+}
+
+// WithPolicySet sets the set of policies.
+func WithPolicySet(ps *cedar.PolicySet) Opt {
+	return func(o *Opts) {
+		o.PolicySet = ps
+	}
+}
+
+// WithPolicies parses the policies in the Cedar language.
+func WithPolicies(fileName string, document []byte) Opt {
+	return func(o *Opts) {
+		ps, err := cedar.NewPolicySetFromBytes(fileName, document)
+		if err != nil {
+			o.err = errors.Join(o.err, fmt.Errorf("cedar: parsing policies: %w", err))
+			return
+		}
+
+		o.PolicySet = ps
+	}
+}
+
+// WithEntities parses the entities in the Cedar JSON format.
+func WithEntities(document []byte) Opt {
+	return func(o *Opts) {
+		var entities cedar.EntityMap
+		if err := json.Unmarshal(document, &entities); err != nil {
+			o.err = errors.Join(o.err, fmt.Errorf("cedar: parsing entities: %w", err))
+			return
+		}
+
+		o.Entities = entities
+	}
+}
+
+// WithSchema parses the schema in the Cedar schema language.
+func WithSchema(fileName string, document []byte) Opt {
+	return func(o *Opts) {
+		s := &schema.Schema{}
+		s.SetFilename(fileName)
+
+		if err := s.UnmarshalCedar(document); err != nil {
+			o.err = errors.Join(o.err, fmt.Errorf("cedar: parsing schema: %w", err))
+			return
+		}
+
+		o.Schema = s
+	}
+}
+
+// WithActionType sets the entity type of actions without a type.
+func WithActionType(typ string) Opt {
+	return func(o *Opts) {
+		o.ActionType = typ
+	}
+}
+
+// WithSeparator sets the separator of the type and the id.
+func WithSeparator(sep string) Opt {
+	return func(o *Opts) {
+		o.Separator = sep
+	}
+}
+
+// Checker is an authz checker that evaluates Cedar policies.
+type Checker struct {
+	opts Opts
+}
+
+// NewChecker returns a new checker.
+// If a schema is configured the policies and entities are validated against it.
+func NewChecker(opts ...Opt) (*Checker, error) {
+	options := DefaultOpts()
+	options.Configure(opts...)
+
+	if options.err != nil {
+		return nil, options.err
+	}
+
+	if options.PolicySet == nil {
+		return nil, ErrNoPolicies
+	}
+
+	if options.Schema != nil {
+		if err := Validate(options.Schema, options.PolicySet, options.Entities); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Checker{opts: options}, nil
+}
+
+// Validate validates the policies and entities against the schema.
+func Validate(s *schema.Schema, ps *cedar.PolicySet, entities cedar.EntityMap) error {
+	resolved, err := s.Resolve()
+	if err != nil {
+		return fmt.Errorf("cedar: resolving schema: %w", err)
+	}
+
+	v := validate.New(resolved)
+
+	var errs error
+
+	for id, policy := range ps.All() {
+		if err := v.Policy(string(id), (*xast.Policy)(policy.AST())); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("cedar: policy %s: %w", id, err))
+		}
+	}
+
+	if err := v.Entities(entities); err != nil {
+		errs = errors.Join(errs, fmt.Errorf("cedar: entities: %w", err))
+	}
+
+	return errs
+}
+
+// Allowed returns true if the policies permit the principal to perform the action on the object.
+func (c *Checker) Allowed(ctx context.Context, principal authz.AuthzPrincipal, object authz.AuthzObject, action authz.AuthzAction) (bool, error) {
+	decision, err := c.Decide(ctx, principal, object, action)
+	if err != nil {
+		return false, err
+	}
+
+	return decision.Allowed, nil
+}
+
+// Decide evaluates the policies with the request attributes from the context as Cedar context.
+func (c *Checker) Decide(ctx context.Context, principal authz.AuthzPrincipal, object authz.AuthzObject, action authz.AuthzAction) (authz.Decision, error) {
+	start := time.Now()
+
+	req, err := c.Request(ctx, principal, object, action)
+	if err != nil {
+		return authz.Decision{}, err
+	}
+
+	ok, diag := c.opts.PolicySet.IsAuthorized(c.opts.Entities, req)
+
+	policies := make([]string, 0, len(diag.Reasons))
+	for _, r := range diag.Reasons {
+		policies = append(policies, string(r.PolicyID))
+	}
+
+	policy := strings.Join(policies, ", ")
+
+	var decision authz.Decision
+
+	switch {
+	case ok == cedar.Allow:
+		decision = authz.Allow("cedar", fmt.Sprintf("permitted by %s", policy))
+	case policy != "":
+		decision = authz.Deny("cedar", fmt.Sprintf("forbidden by %s", policy))
+	default:
+		decision = authz.Deny("cedar", "no policy permits the request")
+	}
+
+	for _, e := range diag.Errors {
+		decision.Reason += fmt.Sprintf("; %s", e.String())
+	}
+
+	decision.Policy = policy
+	decision.Duration = time.Since(start)
+
+	return decision, nil
+}
+
+// Request returns the Cedar request for the principal, object and action.
+func (c *Checker) Request(ctx context.Context, principal authz.AuthzPrincipal, object authz.AuthzObject, action authz.AuthzAction) (cedar.Request, error) {
+	p, err := c.EntityUID(principal.String(), "")
+	if err != nil {
+		return cedar.Request{}, fmt.Errorf("cedar: principal: %w", err)
+	}
+
+	r, err := c.EntityUID(object.String(), "")
+	if err != nil {
+		return cedar.Request{}, fmt.Errorf("cedar: object: %w", err)
+	}
+
+	a, err := c.EntityUID(action.String(), c.opts.ActionType)
+	if err != nil {
+		return cedar.Request{}, fmt.Errorf("cedar: action: %w", err)
+	}
+
+	record, err := Record(authz.GetAttributes(ctx))
+	if err != nil {
+		return cedar.Request{}, fmt.Errorf("cedar: context: %w", err)
+	}
+
+	return cedar.Request{Principal: p, Action: a, Resource: r, Context: record}, nil
+}
+
+// Record converts the attributes into a Cedar record.
+// Cedar has no null, so nil values are skipped.
+func Record(attrs authz.Attributes) (cedar.Record, error) {
+	b, err := json.Marshal(attrs)
+	if err != nil {
+		return cedar.Record{}, err
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return cedar.Record{}, err
+	}
+
+	b, err = json.Marshal(withoutNulls(v))
+	if err != nil {
+		return cedar.Record{}, err
+	}
+
+	var record cedar.Record
+	if err := json.Unmarshal(b, &record); err != nil {
+		return cedar.Record{}, err
+	}
+
+	return record, nil
+}
+
+func withoutNulls(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			if e != nil {
+				m[k] = withoutNulls(e)
+			}
+		}
+
+		return m
+	case []interface{}:
+		s := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			if e != nil {
+				s = append(s, withoutNulls(e))
+			}
+		}
+
+		return s
+	default:
+		return v
+	}
+}
+
+// EntityUID converts a type:id string into a Cedar entity UID.
+// The type can be namespaced, e.g. App::User:alice, and the id can contain the separator.
+// If the string has no type the default type is used.
+func (c *Checker) EntityUID(s, defaultType string) (cedar.EntityUID, error) {
+	typ, id, ok := cutEntity(s, c.opts.Separator)
+	if !ok {
+		typ, id = defaultType, s
+	}
+
+	if typ == "" || id == "" {
+		return cedar.EntityUID{}, fmt.Errorf("invalid entity %q, expected type%sid", s, c.opts.Separator)
+	}
+
+	return cedar.NewEntityUID(cedar.EntityType(typ), cedar.String(id)), nil
+}
+
+// cutEntity splits the type and the id at the first separator
+// that is not part of the :: namespace separator of the type.
+func cutEntity(s, sep string) (string, string, bool) {
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "::") {
+			i += 2
+			continue
+		}
+
+		if strings.HasPrefix(s[i:], sep) {
+			return s[:i], s[i+len(sep):], true
+		}
+
+		i++
+	}
+
+	return "", "", false
+}
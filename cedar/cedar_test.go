@@ -0,0 +1,160 @@
+package cedar_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	authz "github.com/zeiss/fiber-authz"
+	"github.com/zeiss/fiber-authz/cedar"
+)
+
+const schema = `
+entity team;
+entity user in [team];
+entity workload in [team] {
+	public: Bool,
+};
+
+action read appliesTo {
+	principal: user,
+	resource: workload,
+	context: {
+		ip?: String,
+	},
+};
+`
+
+const policies = `
+permit (
+	principal in team::"zeiss",
+	action == Action::"read",
+	resource in team::"zeiss"
+);
+
+permit (
+	principal,
+	action == Action::"read",
+	resource
+) when { context has ip && context.ip == "10.0.0.1" };
+`
+
+const entities = `[
+	{"uid": {"type": "team", "id": "zeiss"}, "attrs": {}, "parents": []},
+	{"uid": {"type": "user", "id": "alice"}, "attrs": {}, "parents": [{"type": "team", "id": "zeiss"}]},
+	{"uid": {"type": "user", "id": "bob"}, "attrs": {}, "parents": []},
+	{"uid": {"type": "workload", "id": "foo"}, "attrs": {"public": false}, "parents": [{"type": "team", "id": "zeiss"}]}
+]`
+
+func TestChecker(t *testing.T) {
+	t.Parallel()
+
+	checker, err := cedar.NewChecker(
+		cedar.WithPolicies("policies.cedar", []byte(policies)),
+		cedar.WithEntities([]byte(entities)),
+		cedar.WithSchema("schema.cedarschema", []byte(schema)),
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		principal authz.AuthzPrincipal
+		allowed   bool
+		policy    string
+	}{
+		{
+			name:      "team member",
+			ctx:       context.Background(),
+			principal: "user:alice",
+			allowed:   true,
+			policy:    "policy0",
+		},
+		{
+			name:      "not a team member",
+			ctx:       context.Background(),
+			principal: "user:bob",
+		},
+		{
+			name:      "allowed by context",
+			ctx:       authz.WithAttributes(context.Background(), authz.Attributes{"ip": "10.0.0.1"}),
+			principal: "user:bob",
+			allowed:   true,
+			policy:    "policy1",
+		},
+		{
+			name:      "nil attributes",
+			ctx:       authz.WithAttributes(context.Background(), authz.Attributes{"ip": "10.0.0.1", "subject": nil, "context": map[string]interface{}(nil)}),
+			principal: "user:bob",
+			allowed:   true,
+			policy:    "policy1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := checker.Decide(tt.ctx, tt.principal, "workload:foo", "read")
+			require.NoError(t, err)
+			require.Equal(t, tt.allowed, decision.Allowed)
+			require.Equal(t, tt.policy, decision.Policy)
+		})
+	}
+}
+
+func TestCheckerInvalidEntity(t *testing.T) {
+	t.Parallel()
+
+	checker, err := cedar.NewChecker(cedar.WithPolicies("policies.cedar", []byte(policies)))
+	require.NoError(t, err)
+
+	_, err = checker.Allowed(context.Background(), "alice", "workload:foo", "read")
+	require.Error(t, err)
+}
+
+const namespacedPolicies = `
+permit (
+	principal == App::User::"alice",
+	action == App::Action::"read",
+	resource == App::Document::"urn:doc:1"
+);
+`
+
+func TestCheckerNamespacedEntity(t *testing.T) {
+	t.Parallel()
+
+	checker, err := cedar.NewChecker(
+		cedar.WithPolicies("policies.cedar", []byte(namespacedPolicies)),
+		cedar.WithActionType("App::Action"),
+	)
+	require.NoError(t, err)
+
+	uid, err := checker.EntityUID("App::User:alice", "")
+	require.NoError(t, err)
+	require.Equal(t, "App::User", string(uid.Type))
+	require.Equal(t, "alice", string(uid.ID))
+
+	uid, err = checker.EntityUID("App::Document:urn:doc:1", "")
+	require.NoError(t, err)
+	require.Equal(t, "App::Document", string(uid.Type))
+	require.Equal(t, "urn:doc:1", string(uid.ID))
+
+	allowed, err := checker.Allowed(context.Background(), "App::User:alice", "App::Document:urn:doc:1", "read")
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	allowed, err = checker.Allowed(context.Background(), "App::User:bob", "App::Document:urn:doc:1", "read")
+	require.NoError(t, err)
+	require.False(t, allowed)
+}
+
+func TestCheckerSchemaValidation(t *testing.T) {
+	t.Parallel()
+
+	invalid := `permit (principal, action == Action::"delete", resource);`
+
+	_, err := cedar.NewChecker(
+		cedar.WithPolicies("policies.cedar", []byte(invalid)),
+		cedar.WithSchema("schema.cedarschema", []byte(schema)),
+	)
+	require.Error(t, err)
+}
@@ -0,0 +1,187 @@
+package cel
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	celgo "cel.dev/cel-go/cel"
+	"cel.dev/cel-go/common/types"
+	"cel.dev/cel-go/common/types/ref"
+	"github.com/gofiber/fiber/v2"
+	authz "github.com/zeiss/fiber-authz"
+	"github.com/zeiss/fiber-authz/oas/oidc"
+)
+
+// Input is the input of a condition.
+// The fields are available as variables with the same name in lower case,
+// the time is available as now.
+type Input struct {
+	// Principal is the subject.
+	Principal authz.AuthzPrincipal
+	// Object is the object.
+	Object authz.AuthzObject
+	// Action is the action.
+	Action authz.AuthzAction
+	// Method is the HTTP method of the request.
+	Method string
+	// Path is the path of the request.
+	Path string
+	// Headers are the request headers with lower case names.
+	Headers map[string]string
+	// IP is the client IP.
+	IP string
+	// Time is the time of the request.
+	Time time.Time
+	// Claims are the JWT claims.
+	Claims map[string]interface{}
+}
+
+// NewInput returns the input from the request.
+// The claims are taken from the OIDC context.
+func NewInput(c *fiber.Ctx) Input {
+	headers := make(map[string]string)
+	for k, v := range c.GetReqHeaders() {
+		headers[strings.ToLower(k)] = strings.Join(v, ",")
+	}
+
+	claims := map[string]interface{}{}
+	if jwt, ok := oidc.GetJWTFromContext(c.UserContext()); ok && jwt.Claims != nil {
+		claims = jwt.Claims
+	}
+
+	return Input{
+		Method:  c.Method(),
+		Path:    c.Path(),
+		Headers: headers,
+		IP:      c.IP(),
+		Time:    time.Now(),
+		Claims:  claims,
+	}
+}
+
+func (i Input) activation() map[string]interface{} {
+	headers := i.Headers
+	if headers == nil {
+		headers = map[string]string{}
+	}
+
+	claims := i.Claims
+	if claims == nil {
+		claims = map[string]interface{}{}
+	}
+
+	return map[string]interface{}{
+		"principal": i.Principal.String(),
+		"object":    i.Object.String(),
+		"action":    i.Action.String(),
+		"method":    i.Method,
+		"path":      i.Path,
+		"headers":   headers,
+		"ip":        i.IP,
+		"now":       i.Time,
+		"claims":    claims,
+	}
+}
+
+var env = sync.OnceValues(NewEnv)
+
+// NewEnv returns the CEL environment of the conditions.
+//
+// In addition to the standard library it provides inCIDR(ip, cidr).
+func NewEnv() (*celgo.Env, error) {
+	return celgo.NewEnv(
+		celgo.Variable("principal", celgo.StringType),
+		celgo.Variable("object", celgo.StringType),
+		celgo.Variable("action", celgo.StringType),
+		celgo.Variable("method", celgo.StringType),
+		celgo.Variable("path", celgo.StringType),
+		celgo.Variable("headers", celgo.MapType(celgo.StringType, celgo.StringType)),
+		celgo.Variable("ip", celgo.StringType),
+		celgo.Variable("now", celgo.TimestampType),
+		celgo.Variable("claims", celgo.MapType(celgo.StringType, celgo.DynType)),
+		celgo.Function("inCIDR",
+			celgo.Overload("in_cidr_string_string", []*celgo.Type{celgo.StringType, celgo.StringType}, celgo.BoolType,
+				celgo.BinaryBinding(inCIDR),
+			),
+		),
+	)
+}
+
+func inCIDR(lhs, rhs ref.Val) ref.Val {
+	ip := net.ParseIP(string(lhs.(types.String)))
+	if ip == nil {
+		return types.False
+	}
+
+	_, network, err := net.ParseCIDR(string(rhs.(types.String)))
+	if err != nil {
+		return types.NewErr("invalid cidr %q", rhs)
+	}
+
+	return types.Bool(network.Contains(ip))
+}
+
+// Condition is a compiled CEL expression that evaluates to a bool.
+type Condition struct {
+	expr string
+	prg  celgo.Program
+}
+
+// Compile compiles the expression.
+// It returns an error if the expression is invalid or does not evaluate to a bool.
+func Compile(expr string) (*Condition, error) {
+	e, err := env()
+	if err != nil {
+		return nil, err
+	}
+
+	ast, iss := e.Compile(expr)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("cel: compiling %q: %w", expr, iss.Err())
+	}
+
+	if ast.OutputType() != celgo.BoolType {
+		return nil, fmt.Errorf("cel: %q must evaluate to bool, got %s", expr, ast.OutputType())
+	}
+
+	prg, err := e.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("cel: %q: %w", expr, err)
+	}
+
+	return &Condition{expr: expr, prg: prg}, nil
+}
+
+// MustCompile compiles the expression and panics on error.
+func MustCompile(expr string) *Condition {
+	c, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// String returns the expression.
+func (c *Condition) String() string {
+	return c.expr
+}
+
+// Eval evaluates the condition with the input.
+func (c *Condition) Eval(ctx context.Context, in Input) (bool, error) {
+	out, _, err := c.prg.ContextEval(ctx, in.activation())
+	if err != nil {
+		return false, fmt.Errorf("cel: evaluating %q: %w", c.expr, err)
+	}
+
+	allowed, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("cel: %q did not evaluate to bool", c.expr)
+	}
+
+	return allowed, nil
+}
@@ -0,0 +1,168 @@
+package cel_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+	authz "github.com/zeiss/fiber-authz"
+	"github.com/zeiss/fiber-authz/cel"
+)
+
+func TestCondition(t *testing.T) {
+	t.Parallel()
+
+	monday := time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		expr    string
+		in      cel.Input
+		allowed bool
+	}{
+		{
+			name:    "business hours",
+			expr:    `now.getHours() >= 9 && now.getHours() < 17`,
+			in:      cel.Input{Time: monday},
+			allowed: true,
+		},
+		{
+			name: "outside business hours",
+			expr: `now.getHours() >= 9 && now.getHours() < 17`,
+			in:   cel.Input{Time: monday.Add(10 * time.Hour)},
+		},
+		{
+			name:    "corporate network",
+			expr:    `inCIDR(ip, "10.0.0.0/8")`,
+			in:      cel.Input{IP: "10.1.2.3"},
+			allowed: true,
+		},
+		{
+			name: "public network",
+			expr: `inCIDR(ip, "10.0.0.0/8")`,
+			in:   cel.Input{IP: "192.168.0.1"},
+		},
+		{
+			name:    "claims and principal",
+			expr:    `"admin" in claims.roles && principal == "user:alice"`,
+			in:      cel.Input{Principal: "user:alice", Claims: map[string]interface{}{"roles": []interface{}{"admin"}}},
+			allowed: true,
+		},
+		{
+			name:    "headers",
+			expr:    `headers["x-tenant"] == "zeiss" && action == "read"`,
+			in:      cel.Input{Action: "read", Headers: map[string]string{"x-tenant": "zeiss"}},
+			allowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond, err := cel.Compile(tt.expr)
+			require.NoError(t, err)
+
+			allowed, err := cond.Eval(context.Background(), tt.in)
+			require.NoError(t, err)
+			require.Equal(t, tt.allowed, allowed)
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, expr := range []string{`principal ==`, `unknown == "foo"`, `principal`} {
+		_, err := cel.Compile(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		checker authz.AuthzChecker
+		ip      string
+		status  int
+	}{
+		{
+			name:   "condition only",
+			ip:     "10.0.0.1",
+			status: http.StatusOK,
+		},
+		{
+			name:   "condition not satisfied",
+			ip:     "192.168.0.1",
+			status: http.StatusForbidden,
+		},
+		{
+			name:    "checker allows",
+			checker: authz.NewFake(true),
+			ip:      "10.0.0.1",
+			status:  http.StatusOK,
+		},
+		{
+			name:    "checker denies",
+			checker: authz.NewFake(false),
+			ip:      "10.0.0.1",
+			status:  http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", cel.Authenticate(func(c *fiber.Ctx) error {
+				return c.SendStatus(http.StatusOK)
+			}, cel.Config{
+				Checker:   tt.checker,
+				Condition: cel.MustCompile(`inCIDR(headers["x-real-ip"], "10.0.0.0/8")`),
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Real-Ip", tt.ip)
+
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, tt.status, res.StatusCode)
+		})
+	}
+}
+
+func TestCompileSpec(t *testing.T) {
+	t.Parallel()
+
+	spec := `
+openapi: 3.0.0
+info:
+  title: test
+  version: 1.0.0
+paths:
+  /workloads:
+    get:
+      x-fiber-authz-cel: 'method == "GET"'
+      responses:
+        "200":
+          description: ok
+    post:
+      x-fiber-authz-cel: 'method =='
+      responses:
+        "200":
+          description: ok
+`
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+
+	conds, err := cel.CompileSpec(doc)
+	require.ErrorContains(t, err, "POST /workloads")
+
+	_, ok := conds.Get(http.MethodGet, "/workloads")
+	require.True(t, ok)
+}
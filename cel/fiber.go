@@ -0,0 +1,166 @@
+package cel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	authz "github.com/zeiss/fiber-authz"
+)
+
+// Config is the configuration of the middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	Next func(c *fiber.Ctx) bool
+
+	// Condition is the condition that must be satisfied.
+	Condition *Condition
+
+	// Checker is evaluated before the condition.
+	//
+	// Optional. Default: the condition replaces the checker.
+	Checker authz.AuthzChecker
+
+	// ObjectResolver is the object resolver.
+	ObjectResolver authz.AuthzObjectResolver
+
+	// ActionResolver is the action resolver.
+	ActionResolver authz.AuthzActionResolver
+
+	// PrincipalResolver is the principal resolver.
+	PrincipalResolver authz.AuthzPrincipalResolver
+
+	// InputResolver returns the input of the condition.
+	//
+	// Optional. Default: NewInput
+	InputResolver func(c *fiber.Ctx) Input
+
+	// ErrorHandler is executed when an error is returned from fiber.Handler.
+	//
+	// Optional. Default: DefaultErrorHandler
+	ErrorHandler fiber.ErrorHandler
+}
+
+// ConfigDefault is the default config.
+var ConfigDefault = Config{
+	ErrorHandler:      defaultErrorHandler,
+	ObjectResolver:    authz.NewNoopObjectResolver(),
+	PrincipalResolver: authz.NewNoopPrincipalResolver(),
+	ActionResolver:    authz.NewNoopActionResolver(),
+	InputResolver:     NewInput,
+}
+
+// default ErrorHandler that process return error from fiber.Handler
+func defaultErrorHandler(_ *fiber.Ctx, _ error) error {
+	return fiber.ErrBadRequest
+}
+
+// Authenticate is a middleware that evaluates the checker and the condition.
+// The condition is only evaluated if the checker allows the request.
+func Authenticate(handler fiber.Handler, config ...Config) fiber.Handler {
+	cfg := configDefault(config...)
+
+	if cfg.Condition == nil {
+		panic("cel: no condition configured")
+	}
+
+	return func(c *fiber.Ctx) error {
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		object, err := cfg.ObjectResolver.Resolve(c)
+		if err != nil {
+			return err
+		}
+
+		principal, err := cfg.PrincipalResolver.Resolve(c)
+		if err != nil {
+			return err
+		}
+
+		action, err := cfg.ActionResolver.Resolve(c)
+		if err != nil {
+			return err
+		}
+
+		in := cfg.InputResolver(c)
+		in.Principal, in.Object, in.Action = principal, object, action
+
+		// nolint: contextcheck
+		decision, err := Decide(c.UserContext(), cfg.Checker, cfg.Condition, in)
+		if err != nil {
+			return cfg.ErrorHandler(c, err)
+		}
+
+		// nolint: contextcheck
+		c.SetUserContext(authz.WithAuthzDecision(c.UserContext(), authz.NewAuthzContext(principal, object, action), decision))
+
+		if !decision.Allowed {
+			return c.SendStatus(fiber.StatusForbidden)
+		}
+
+		return handler(c)
+	}
+}
+
+// Decide evaluates the checker and the condition with the input.
+// If the checker is nil only the condition is evaluated.
+func Decide(ctx context.Context, checker authz.AuthzChecker, cond *Condition, in Input) (authz.Decision, error) {
+	start := time.Now()
+
+	if checker != nil {
+		decision, err := authz.Decide(ctx, checker, in.Principal, in.Object, in.Action)
+		if err != nil || !decision.Allowed {
+			return decision, err
+		}
+	}
+
+	ok, err := cond.Eval(ctx, in)
+	if err != nil {
+		return authz.Decision{}, err
+	}
+
+	decision := authz.Deny("cel", fmt.Sprintf("condition %s is not satisfied", cond))
+	if ok {
+		decision = authz.Allow("cel", fmt.Sprintf("condition %s is satisfied", cond))
+	}
+
+	decision.Policy = cond.String()
+	decision.Duration = time.Since(start)
+
+	return decision, nil
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = ConfigDefault.ErrorHandler
+	}
+
+	if cfg.ObjectResolver == nil {
+		cfg.ObjectResolver = ConfigDefault.ObjectResolver
+	}
+
+	if cfg.PrincipalResolver == nil {
+		cfg.PrincipalResolver = ConfigDefault.PrincipalResolver
+	}
+
+	if cfg.ActionResolver == nil {
+		cfg.ActionResolver = ConfigDefault.ActionResolver
+	}
+
+	if cfg.InputResolver == nil {
+		cfg.InputResolver = ConfigDefault.InputResolver
+	}
+
+	return cfg
+}
@@ -0,0 +1,108 @@
+package cel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gofiber/fiber/v2"
+	middleware "github.com/oapi-codegen/fiber-middleware"
+	authz "github.com/zeiss/fiber-authz"
+)
+
+// DefaultExtensionName is the default extension name.
+const DefaultExtensionName = "x-fiber-authz-cel"
+
+// Conditions are the compiled conditions of the operations.
+type Conditions map[string]*Condition
+
+// Get returns the condition of the operation.
+func (c Conditions) Get(method, path string) (*Condition, bool) {
+	cond, ok := c[OperationKey(method, path)]
+
+	return cond, ok
+}
+
+// OperationKey returns the key of the operation.
+func OperationKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// CompileSpec compiles the conditions of all operations that have the extension.
+// It returns all compile errors with the operation they belong to.
+func CompileSpec(doc *openapi3.T, name ...string) (Conditions, error) {
+	ext := DefaultExtensionName
+	if len(name) > 0 {
+		ext = name[0]
+	}
+
+	conds := Conditions{}
+
+	if doc.Paths == nil {
+		return conds, nil
+	}
+
+	var errs error
+
+	for path, pathItem := range doc.Paths.Map() {
+		for method, op := range pathItem.Operations() {
+			v, ok := op.Extensions[ext]
+			if !ok {
+				continue
+			}
+
+			expr, ok := v.(string)
+			if !ok {
+				errs = errors.Join(errs, fmt.Errorf("%s %s: %s must be a string", method, path, ext))
+				continue
+			}
+
+			cond, err := Compile(expr)
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("%s %s: %w", method, path, err))
+				continue
+			}
+
+			conds[OperationKey(method, path)] = cond
+		}
+	}
+
+	return conds, errs
+}
+
+// OasAuthenticate is an authentication function that evaluates the condition of the operation.
+// The principal, object and action are taken from the authz context if a previous
+// authentication function has set it. Operations without a condition are allowed.
+func OasAuthenticate(conds Conditions) openapi3filter.AuthenticationFunc {
+	return func(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+		route := input.RequestValidationInput.Route
+
+		cond, ok := conds.Get(route.Method, route.Path)
+		if !ok {
+			return nil
+		}
+
+		c := middleware.GetFiberContext(ctx)
+
+		in := NewInput(c)
+
+		// nolint: contextcheck
+		if authzCtx, err := authz.GetAuthzContext(c.UserContext()); err == nil {
+			in.Principal, in.Object, in.Action = authzCtx.Principal, authzCtx.Object, authzCtx.Action
+		}
+
+		allowed, err := cond.Eval(ctx, in)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "internal server error")
+		}
+
+		if !allowed {
+			return fiber.NewError(fiber.StatusForbidden, "forbidden")
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,38 @@
+package authz_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jwx/jwt"
+	"github.com/stretchr/testify/require"
+	authz "github.com/zeiss/fiber-authz"
+	"github.com/zeiss/fiber-authz/internal/fake"
+	"github.com/zeiss/fiber-authz/oas"
+)
+
+func TestCheckTokenClaims(t *testing.T) {
+	t.Parallel()
+
+	f, err := fake.NewFakeAuthenticator()
+	require.NoError(t, err)
+
+	jws, err := f.CreateJWSWithClaims([]string{"read:teams"})
+	require.NoError(t, err)
+
+	token, err := f.ValidateJWS(string(jws))
+	require.NoError(t, err)
+
+	require.NoError(t, authz.CheckTokenClaims([]string{"read:teams"}, token))
+	require.ErrorIs(t, authz.CheckTokenClaims([]string{"write:teams"}, token), authz.ErrClaimsInvalid)
+
+	token = jwt.New()
+	require.NoError(t, token.Set("realm_access", map[string]interface{}{"roles": []interface{}{"admin"}}))
+	require.NoError(t, token.Set("scope", "read:teams write:teams"))
+
+	opts := []oas.ClaimsOpt{oas.WithClaimPaths("scope", "realm_access.roles")}
+	require.NoError(t, authz.CheckTokenClaims([]string{"write:teams", "admin"}, token, opts...))
+
+	require.NoError(t, token.Set(authz.PermissionsClaim, []interface{}{"delete:teams"}))
+	require.ErrorIs(t, authz.CheckTokenClaims([]string{"delete:teams"}, token, opts...), authz.ErrClaimsInvalid)
+	require.NoError(t, authz.CheckTokenClaims([]string{"delete:teams", "admin"}, token, oas.WithAdditionalClaimPaths("realm_access.roles")))
+}
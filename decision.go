@@ -0,0 +1,96 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zeiss/fiber-authz/openfga"
+)
+
+// ErrNoAuthzDecision is the error returned when the decision is not found.
+var ErrNoAuthzDecision = errors.New("no authz decision")
+
+// Decision is the result of an authorization check.
+type Decision struct {
+	// Allowed is true if the principal is allowed to perform the action on the object.
+	Allowed bool `json:"allowed"`
+	// Reason is a human readable explanation of the decision.
+	Reason string `json:"reason,omitempty"`
+	// Policy is the policy, relation or role that matched.
+	Policy string `json:"policy,omitempty"`
+	// Checker is the name of the checker that made the decision.
+	Checker string `json:"checker,omitempty"`
+	// Duration is the time it took to evaluate the decision.
+	Duration time.Duration `json:"duration,omitempty"`
+	// Obligations are additional instructions that come with the decision.
+	Obligations map[string]interface{} `json:"obligations,omitempty"`
+}
+
+// Allow returns a new allowed decision.
+func Allow(checker, reason string) Decision {
+	return Decision{Allowed: true, Checker: checker, Reason: reason}
+}
+
+// Deny returns a new denied decision.
+func Deny(checker, reason string) Decision {
+	return Decision{Allowed: false, Checker: checker, Reason: reason}
+}
+
+// DecisionChecker is the interface that wraps the Decide method.
+type DecisionChecker interface {
+	// Decide returns the decision for the principal to perform the action on the object.
+	Decide(context.Context, AuthzPrincipal, AuthzObject, AuthzAction) (Decision, error)
+}
+
+// Decide returns the decision of the checker.
+// If the checker does not implement the DecisionChecker interface,
+// the result of Allowed is wrapped into a decision.
+func Decide(ctx context.Context, checker AuthzChecker, principal AuthzPrincipal, object AuthzObject, action AuthzAction) (Decision, error) {
+	if dc, ok := checker.(DecisionChecker); ok {
+		return dc.Decide(ctx, principal, object, action)
+	}
+
+	start := time.Now()
+
+	allowed, err := checker.Allowed(ctx, principal, object, action)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	return Decision{Allowed: allowed, Duration: time.Since(start)}, nil
+}
+
+// GetAuthzDecision extracts the Decision from the context.
+// The result of the checks of openfga.OasAuthenticate is returned as a decision
+// with the failed checks as the reason.
+func GetAuthzDecision(ctx context.Context) (Decision, error) {
+	key := ctx.Value(authzDecision)
+
+	if key == nil {
+		if res, ok := openfga.GetResult(ctx); ok {
+			return resultDecision(res), nil
+		}
+
+		return Decision{}, ErrNoAuthzDecision
+	}
+
+	return key.(Decision), nil
+}
+
+// WithAuthzDecision returns a new context with the authz context and decision.
+func WithAuthzDecision(ctx context.Context, authzCtx AuthzContext, decision Decision) context.Context {
+	ctx = context.WithValue(ctx, authzContext, authzCtx)
+
+	return context.WithValue(ctx, authzDecision, decision)
+}
+
+// resultDecision returns the decision of the result of OpenFGA checks.
+func resultDecision(res openfga.Result) Decision {
+	if res.Allowed {
+		return Allow("fga", "checks are allowed")
+	}
+
+	return Deny("fga", fmt.Sprintf("failed checks: %s", res.Reason()))
+}
@@ -2,12 +2,12 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"log"
 	"os"
 
-	openfga "github.com/openfga/go-sdk"
 	"github.com/openfga/go-sdk/client"
+	"github.com/zeiss/fiber-authz/openfga"
+	"github.com/zeiss/fiber-authz/openfga/model"
 )
 
 func main() {
@@ -28,19 +28,28 @@ func main() {
 	log.Println(resp.Id)
 	fgaClient.SetStoreId(resp.Id)
 
-	// Read in example.json file into string.
-	model, err := os.ReadFile("./examples/fga/example.json")
+	// Read in the model in the DSL or JSON format.
+	path := "./examples/fga/example.json"
+	if len(os.Args) > 1 {
+		path = os.Args[1]
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+
+	body, err := model.Parse(b)
 	if err != nil {
 		panic(err)
 	}
 
-	var body openfga.WriteAuthorizationModelRequest
-	if err := json.Unmarshal(model, &body); err != nil {
+	if err := model.Validate(body); err != nil {
 		panic(err)
 	}
 
 	data, err := fgaClient.WriteAuthorizationModel(context.Background()).
-		Body(body).
+		Body(*body).
 		Execute()
 	if err != nil {
 		panic(err)
@@ -48,29 +57,13 @@ func main() {
 
 	log.Println(data.AuthorizationModelId)
 
-	options := client.ClientWriteOptions{
-		AuthorizationModelId: openfga.PtrString(data.AuthorizationModelId),
-	}
+	checker := openfga.NewClient(fgaClient, openfga.WithModelID(data.AuthorizationModelId))
 
-	writes := client.ClientWriteRequest{
-		Writes: []client.ClientTupleKey{
-			{
-				User:     "team:zeiss",
-				Relation: "team",
-				Object:   "workload:foo",
-			},
-			{
-				User:     "user:katallaxie",
-				Relation: "editor",
-				Object:   "team:zeiss",
-			},
-		},
-	}
-
-	write, err := fgaClient.Write(context.Background()).Body(writes).Options(options).Execute()
+	err = checker.Write(context.Background(),
+		openfga.NewTuple(openfga.NewUser(openfga.Namespace("team"), openfga.String("zeiss")), openfga.NewRelation(openfga.String("team")), openfga.NewObject(openfga.Namespace("workload"), openfga.String("foo"))),
+		openfga.NewTuple(openfga.NewUser(openfga.Namespace("user"), openfga.String("katallaxie")), openfga.NewRelation(openfga.String("editor")), openfga.NewObject(openfga.Namespace("team"), openfga.String("zeiss"))),
+	)
 	if err != nil {
 		panic(err)
 	}
-
-	log.Println(write.Writes)
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/openfga/go-sdk/client"
 	authz "github.com/zeiss/fiber-authz"
+	"github.com/zeiss/fiber-authz/openfga/model/cmd"
 
 	"github.com/gofiber/fiber/v2"
 	ll "github.com/gofiber/fiber/v2/middleware/logger"
@@ -53,6 +54,8 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfg.Flags.DB.Password, "db-password", cfg.Flags.DB.Password, "Database password")
 	rootCmd.PersistentFlags().IntVar(&cfg.Flags.DB.Port, "db-port", cfg.Flags.DB.Port, "Database port")
 
+	rootCmd.AddCommand(cmd.NewGenerateCmd())
+
 	rootCmd.SilenceUsage = true
 }
 
@@ -82,6 +85,7 @@ func run(_ context.Context) error {
 	}
 
 	app.Post("/check", authz.NewCheckerHandler(config))
+	app.Post("/check/batch", authz.NewBatchCheckerHandler(config))
 
 	err = app.Listen(cfg.Flags.Addr)
 	if err != nil {
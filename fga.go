@@ -2,19 +2,56 @@ package authz
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/openfga/go-sdk/client"
+	"github.com/zeiss/fiber-authz/openfga"
 )
 
-var _ AuthzChecker = (*fga)(nil)
+var (
+	_ AuthzChecker    = (*fga)(nil)
+	_ DecisionChecker = (*fga)(nil)
+	_ BatchChecker    = (*fga)(nil)
+
+	_ openfga.StoreScoper = (*fga)(nil)
+)
 
 type fga struct {
 	client *client.OpenFgaClient
+	opts   *openfga.CheckOptions
+}
+
+// NewFGA returns a new FGA authz checker.
+// The options pin the authorization model, override the store per tenant and set the consistency.
+func NewFGA(c *client.OpenFgaClient, opts ...openfga.ClientOpt) *fga {
+	return &fga{client: c, opts: openfga.NewCheckOptions(opts...)}
 }
 
-// NewFGA returns a new FGA authz checker
-func NewFGA(c *client.OpenFgaClient) *fga {
-	return &fga{client: c}
+// ModelID returns the id of the pinned authorization model.
+func (f *fga) ModelID() string {
+	return f.opts.ModelID()
+}
+
+// SetModelID pins the checks to the authorization model.
+func (f *fga) SetModelID(id string) {
+	f.opts.SetModelID(id)
+}
+
+// ReloadModelID pins the checks to the latest authorization model of the store.
+func (f *fga) ReloadModelID(ctx context.Context) error {
+	return f.opts.ReloadModelID(ctx, f.client)
+}
+
+// StoreScope returns the store and authorization model of the checks of the request.
+func (f *fga) StoreScope(ctx context.Context) (string, error) {
+	return f.opts.StoreScope(ctx)
+}
+
+// ReloadModelIDEvery reloads the latest authorization model in the interval until the context is done.
+func (f *fga) ReloadModelIDEvery(ctx context.Context, interval time.Duration) {
+	f.opts.ReloadModelIDEvery(ctx, f.client, interval)
 }
 
 // AuthzFGAUser is the subject.
@@ -30,16 +67,110 @@ type AuthzFGAAction = AuthzAction
 // Returns an error if the request fails.
 // The principal is the object, the user is the subject, and the permission is the relation.
 func (f *fga) Allowed(ctx context.Context, user AuthzFGAUser, relation AuthzFGARelation, object AuthzFGAAction) (bool, error) {
+	decision, err := f.Decide(ctx, user, relation, object)
+	if err != nil {
+		return false, err
+	}
+
+	return decision.Allowed, nil
+}
+
+// Decide returns the decision of the OpenFGA check.
+// The matched policy is the relation that has been checked.
+// The contextual tuples and the condition context of the context are sent with the check,
+// see openfga.WithContextualTuples and openfga.WithConditionContext.
+func (f *fga) Decide(ctx context.Context, user AuthzFGAUser, relation AuthzFGARelation, object AuthzFGAAction) (Decision, error) {
+	start := time.Now()
+
 	body := client.ClientCheckRequest{
-		User:     user.String(),
-		Relation: relation.String(),
-		Object:   object.String(),
+		User:             user.String(),
+		Relation:         relation.String(),
+		Object:           object.String(),
+		ContextualTuples: openfga.ClientContextualTuples(openfga.GetContextualTuples(ctx)),
+		Context:          openfga.ClientConditionContext(openfga.GetConditionContext(ctx)),
 	}
 
-	allowed, err := f.client.Check(ctx).Body(body).Execute()
+	opts, err := f.opts.CheckRequestOptions(ctx)
 	if err != nil {
-		return false, err
+		return Decision{}, err
+	}
+
+	allowed, err := f.client.Check(ctx).Body(body).Options(opts).Execute()
+	if err != nil {
+		return Decision{}, err
+	}
+
+	decision := fgaDecision(user, relation, object, allowed.GetAllowed())
+	decision.Duration = time.Since(start)
+
+	return decision, nil
+}
+
+// BatchDecide returns a decision for every check using the OpenFGA BatchCheck API.
+// Checks that fail on the server are denied with the error as the reason.
+func (f *fga) BatchDecide(ctx context.Context, checks []AuthzParams) ([]Decision, error) {
+	if len(checks) == 0 {
+		return []Decision{}, nil
 	}
 
-	return allowed.GetAllowed(), nil
+	start := time.Now()
+
+	body := client.ClientBatchCheckRequest{
+		Checks: make([]client.ClientBatchCheckItem, len(checks)),
+	}
+
+	contextualTuples := openfga.ClientContextualTuples(openfga.GetContextualTuples(ctx))
+	conditionContext := openfga.ClientConditionContext(openfga.GetConditionContext(ctx))
+
+	for i, check := range checks {
+		body.Checks[i] = client.ClientBatchCheckItem{
+			User:             check.Principal.String(),
+			Relation:         check.Object.String(),
+			Object:           check.Action.String(),
+			CorrelationId:    strconv.Itoa(i),
+			ContextualTuples: contextualTuples,
+			Context:          conditionContext,
+		}
+	}
+
+	opts, err := f.opts.BatchCheckRequestOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := f.client.BatchCheck(ctx).Body(body).Options(opts).Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	results := res.GetResult()
+	decisions := make([]Decision, len(checks))
+
+	for i, check := range checks {
+		result, ok := results[strconv.Itoa(i)]
+		if !ok {
+			return nil, fmt.Errorf("missing result for check %d", i)
+		}
+
+		decisions[i] = fgaDecision(check.Principal, check.Object, check.Action, result.GetAllowed())
+		decisions[i].Duration = time.Since(start)
+
+		if result.Error != nil {
+			decisions[i].Allowed = false
+			decisions[i].Reason = fmt.Sprintf("check failed: %s", result.Error.GetMessage())
+		}
+	}
+
+	return decisions, nil
+}
+
+func fgaDecision(user AuthzFGAUser, relation AuthzFGARelation, object AuthzFGAAction, allowed bool) Decision {
+	decision := Deny("fga", fmt.Sprintf("%s has no relation %s on %s", user, relation, object))
+	if allowed {
+		decision = Allow("fga", fmt.Sprintf("%s has relation %s on %s", user, relation, object))
+	}
+
+	decision.Policy = relation.String()
+
+	return decision
 }
@@ -0,0 +1,279 @@
+package authz
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Headers of the original request that are set by proxies.
+const (
+	// HeaderXForwardedMethod is set by Traefik forwardAuth.
+	HeaderXForwardedMethod = "X-Forwarded-Method"
+	// HeaderXForwardedURI is set by Traefik forwardAuth.
+	HeaderXForwardedURI = "X-Forwarded-Uri"
+	// HeaderXOriginalMethod is commonly set for NGINX auth_request.
+	HeaderXOriginalMethod = "X-Original-Method"
+	// HeaderXOriginalURI is commonly set for NGINX auth_request.
+	HeaderXOriginalURI = "X-Original-URI"
+	// HeaderXEnvoyOriginalPath is set by Envoy when the path has been rewritten.
+	HeaderXEnvoyOriginalPath = "X-Envoy-Original-Path"
+)
+
+// ForwardAuthProxy is the proxy in front of the forward auth handler.
+// It selects the only headers that are trusted to carry the original request.
+type ForwardAuthProxy int
+
+const (
+	// ForwardAuthProxyNone uses the method and URI of the auth request as is.
+	ForwardAuthProxyNone ForwardAuthProxy = iota
+	// ForwardAuthProxyTraefik reads the X-Forwarded-Method and X-Forwarded-Uri headers.
+	ForwardAuthProxyTraefik
+	// ForwardAuthProxyNGINX reads the X-Original-Method and X-Original-URI headers.
+	ForwardAuthProxyNGINX
+	// ForwardAuthProxyEnvoy uses the method of the auth request and the
+	// X-Envoy-Original-Path header or, if it is not set, the URI of the auth request.
+	ForwardAuthProxyEnvoy
+)
+
+// headers returns the method and URI headers of the proxy.
+func (p ForwardAuthProxy) headers() (method, uri string) {
+	switch p {
+	case ForwardAuthProxyTraefik:
+		return HeaderXForwardedMethod, HeaderXForwardedURI
+	case ForwardAuthProxyNGINX:
+		return HeaderXOriginalMethod, HeaderXOriginalURI
+	case ForwardAuthProxyEnvoy:
+		return "", HeaderXEnvoyOriginalPath
+	default:
+		return "", ""
+	}
+}
+
+// Headers that are returned to the proxy and can be forwarded upstream.
+const (
+	HeaderXAuthzPrincipal = "X-Authz-Principal"
+	HeaderXAuthzObject    = "X-Authz-Object"
+	HeaderXAuthzAction    = "X-Authz-Action"
+)
+
+// ForwardAuthHeaderFunc returns the value of an upstream header.
+type ForwardAuthHeaderFunc func(AuthzContext, Decision) string
+
+// ForwardAuthConfig is the config of the forward auth handler.
+type ForwardAuthConfig struct {
+	// Next defines a function to skip this middleware when returned true.
+	Next func(c *fiber.Ctx) bool
+
+	// Checker is implementing the AuthzChecker interface.
+	Checker AuthzChecker
+
+	// ObjectResolver is the object resolver.
+	ObjectResolver AuthzObjectResolver
+
+	// ActionResolver is the action resolver.
+	ActionResolver AuthzActionResolver
+
+	// PrincipalResolver is the principal resolver.
+	PrincipalResolver AuthzPrincipalResolver
+
+	// AttributesResolver resolves the attributes of the original request.
+	//
+	// Optional. Default: NewRequestAttributesResolver()
+	AttributesResolver AuthzAttributesResolver
+
+	// Proxy is the proxy that sends the auth requests. Only the headers of this
+	// proxy are used to restore the original request, all others are ignored.
+	// The proxy must overwrite these headers if they are sent by the client.
+	//
+	// Optional. Default: ForwardAuthProxyNone
+	Proxy ForwardAuthProxy
+
+	// PathPrefix is stripped from the path of the request,
+	// e.g. the path_prefix of the Envoy ext_authz filter.
+	//
+	// Optional. Default: ""
+	PathPrefix string
+
+	// Headers are the headers that are set on the response for allowed requests.
+	//
+	// Optional. Default: DefaultForwardAuthHeaders()
+	Headers map[string]ForwardAuthHeaderFunc
+
+	// ErrorHandler is executed when an error is returned from a resolver or the checker.
+	//
+	// Optional. Default: JSONErrorHandler
+	ErrorHandler fiber.ErrorHandler
+}
+
+// DefaultForwardAuthHeaders returns the default upstream headers.
+func DefaultForwardAuthHeaders() map[string]ForwardAuthHeaderFunc {
+	return map[string]ForwardAuthHeaderFunc{
+		HeaderXAuthzPrincipal: func(a AuthzContext, _ Decision) string { return a.Principal.String() },
+		HeaderXAuthzObject:    func(a AuthzContext, _ Decision) string { return a.Object.String() },
+		HeaderXAuthzAction:    func(a AuthzContext, _ Decision) string { return a.Action.String() },
+	}
+}
+
+// ForwardAuthConfigDefault is the default config.
+var ForwardAuthConfigDefault = ForwardAuthConfig{
+	ErrorHandler:       JSONErrorHandler,
+	ObjectResolver:     NewNoopObjectResolver(),
+	PrincipalResolver:  NewNoopPrincipalResolver(),
+	ActionResolver:     NewNoopActionResolver(),
+	AttributesResolver: NewRequestAttributesResolver(),
+	Checker:            NewNoop(),
+}
+
+// NewForwardAuthHandler returns a new fiber.Handler for Envoy ext_authz (HTTP mode),
+// Traefik forwardAuth and NGINX auth_request.
+//
+// The original method and URI are restored from the headers of the configured proxy
+// and the path is cleaned before the resolvers are called. It responds with 401 if no principal is resolved,
+// 403 if the checker denies the request and 200 with the upstream headers otherwise.
+func NewForwardAuthHandler(config ...ForwardAuthConfig) fiber.Handler {
+	cfg := forwardAuthConfigDefault(config...)
+
+	return func(c *fiber.Ctx) error {
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		if err := restoreOriginalRequest(c, cfg.Proxy, cfg.PathPrefix); err != nil {
+			return cfg.ErrorHandler(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+		}
+
+		principal, err := cfg.PrincipalResolver.Resolve(c)
+		if err != nil || principal == AuthzNoPrincipial {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		object, err := cfg.ObjectResolver.Resolve(c)
+		if err != nil {
+			return cfg.ErrorHandler(c, err)
+		}
+
+		action, err := cfg.ActionResolver.Resolve(c)
+		if err != nil {
+			return cfg.ErrorHandler(c, err)
+		}
+
+		attrs, err := cfg.AttributesResolver.Resolve(c)
+		if err != nil {
+			return cfg.ErrorHandler(c, err)
+		}
+
+		// The user context carries the contextual data that the resolvers have set.
+		// nolint: contextcheck
+		decision, err := Decide(WithAttributes(c.UserContext(), attrs), cfg.Checker, principal, object, action)
+		if err != nil {
+			return cfg.ErrorHandler(c, err)
+		}
+
+		if !decision.Allowed {
+			return c.SendStatus(fiber.StatusForbidden)
+		}
+
+		authzCtx := NewAuthzContext(principal, object, action)
+		for name, fn := range cfg.Headers {
+			c.Set(name, fn(authzCtx, decision))
+		}
+
+		return c.SendStatus(fiber.StatusOK)
+	}
+}
+
+// restoreOriginalRequest sets the method, path and query of the original request.
+func restoreOriginalRequest(c *fiber.Ctx, proxy ForwardAuthProxy, prefix string) error {
+	methodHeader, uriHeader := proxy.headers()
+
+	if methodHeader != "" {
+		if method := c.Get(methodHeader); method != "" {
+			c.Method(strings.ToUpper(method))
+		}
+	}
+
+	var uri string
+	if uriHeader != "" {
+		uri = c.Get(uriHeader)
+	}
+
+	if uri == "" {
+		uri = string(c.Request().RequestURI())
+	}
+
+	u, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return err
+	}
+
+	p := trimPathPrefix(path.Clean("/"+u.Path), prefix)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+
+	c.Path(p)
+	c.Request().URI().SetQueryString(u.RawQuery)
+
+	return nil
+}
+
+// trimPathPrefix removes the prefix if it matches whole path segments,
+// e.g. /api is removed from /api/teams but not from /apiv2/teams.
+func trimPathPrefix(path, prefix string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return path
+	}
+
+	if path == prefix || strings.HasPrefix(path, prefix+"/") {
+		return strings.TrimPrefix(path, prefix)
+	}
+
+	return path
+}
+
+// Helper function to set default values
+func forwardAuthConfigDefault(config ...ForwardAuthConfig) ForwardAuthConfig {
+	if len(config) < 1 {
+		cfg := ForwardAuthConfigDefault
+		cfg.Headers = DefaultForwardAuthHeaders()
+
+		return cfg
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	if cfg.Checker == nil {
+		cfg.Checker = ForwardAuthConfigDefault.Checker
+	}
+
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = ForwardAuthConfigDefault.ErrorHandler
+	}
+
+	if cfg.ObjectResolver == nil {
+		cfg.ObjectResolver = ForwardAuthConfigDefault.ObjectResolver
+	}
+
+	if cfg.PrincipalResolver == nil {
+		cfg.PrincipalResolver = ForwardAuthConfigDefault.PrincipalResolver
+	}
+
+	if cfg.ActionResolver == nil {
+		cfg.ActionResolver = ForwardAuthConfigDefault.ActionResolver
+	}
+
+	if cfg.AttributesResolver == nil {
+		cfg.AttributesResolver = ForwardAuthConfigDefault.AttributesResolver
+	}
+
+	if cfg.Headers == nil {
+		cfg.Headers = DefaultForwardAuthHeaders()
+	}
+
+	return cfg
+}
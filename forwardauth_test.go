@@ -0,0 +1,185 @@
+package authz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+type headerPrincipalResolver struct{}
+
+func (h *headerPrincipalResolver) Resolve(c *fiber.Ctx) (AuthzPrincipal, error) {
+	return AuthzPrincipal(c.Get("X-User")), nil
+}
+
+type pathObjectResolver struct{}
+
+func (p *pathObjectResolver) Resolve(c *fiber.Ctx) (AuthzObject, error) {
+	return AuthzObject(c.Path() + "?" + c.Query("id")), nil
+}
+
+type methodActionResolver struct{}
+
+func (m *methodActionResolver) Resolve(c *fiber.Ctx) (AuthzAction, error) {
+	return AuthzAction(c.Method()), nil
+}
+
+func TestForwardAuthHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		proxy   ForwardAuthProxy
+		method  string
+		target  string
+		headers map[string]string
+		status  int
+		object  string
+		action  string
+	}{
+		{
+			name:    "traefik",
+			proxy:   ForwardAuthProxyTraefik,
+			method:  http.MethodGet,
+			target:  "/auth",
+			headers: map[string]string{"X-User": "alice", HeaderXForwardedMethod: "post", HeaderXForwardedURI: "/workloads?id=1"},
+			status:  http.StatusOK,
+			object:  "/workloads?1",
+			action:  http.MethodPost,
+		},
+		{
+			name:    "nginx",
+			proxy:   ForwardAuthProxyNGINX,
+			method:  http.MethodGet,
+			target:  "/auth",
+			headers: map[string]string{"X-User": "alice", HeaderXOriginalMethod: "DELETE", HeaderXOriginalURI: "/workloads?id=2"},
+			status:  http.StatusOK,
+			object:  "/workloads?2",
+			action:  http.MethodDelete,
+		},
+		{
+			name:    "envoy",
+			method:  http.MethodPut,
+			target:  "/authz/workloads?id=3",
+			headers: map[string]string{"X-User": "alice"},
+			status:  http.StatusOK,
+			object:  "/workloads?3",
+			action:  http.MethodPut,
+		},
+		{
+			name:    "envoy original path",
+			proxy:   ForwardAuthProxyEnvoy,
+			method:  http.MethodGet,
+			target:  "/authz/rewritten?id=5",
+			headers: map[string]string{"X-User": "alice", HeaderXEnvoyOriginalPath: "/authz/workloads?id=5"},
+			status:  http.StatusOK,
+			object:  "/workloads?5",
+			action:  http.MethodGet,
+		},
+		{
+			name:    "spoofed traefik headers behind nginx",
+			proxy:   ForwardAuthProxyNGINX,
+			method:  http.MethodGet,
+			target:  "/auth",
+			headers: map[string]string{"X-User": "alice", HeaderXForwardedMethod: "GET", HeaderXForwardedURI: "/public", HeaderXOriginalMethod: "POST", HeaderXOriginalURI: "/admin?id=6"},
+			status:  http.StatusOK,
+			object:  "/admin?6",
+			action:  http.MethodPost,
+		},
+		{
+			name:    "spoofed headers without proxy",
+			method:  http.MethodDelete,
+			target:  "/authz/admin?id=7",
+			headers: map[string]string{"X-User": "alice", HeaderXForwardedMethod: "GET", HeaderXForwardedURI: "/public", HeaderXOriginalURI: "/public"},
+			status:  http.StatusOK,
+			object:  "/admin?7",
+			action:  http.MethodDelete,
+		},
+		{
+			name:    "dot segments",
+			proxy:   ForwardAuthProxyTraefik,
+			method:  http.MethodGet,
+			target:  "/auth",
+			headers: map[string]string{"X-User": "alice", HeaderXForwardedMethod: "GET", HeaderXForwardedURI: "/public/../admin/./teams//1?id=8"},
+			status:  http.StatusOK,
+			object:  "/admin/teams/1?8",
+			action:  http.MethodGet,
+		},
+		{
+			name:    "dot segments above the root",
+			proxy:   ForwardAuthProxyNGINX,
+			method:  http.MethodGet,
+			target:  "/auth",
+			headers: map[string]string{"X-User": "alice", HeaderXOriginalMethod: "GET", HeaderXOriginalURI: "/../../admin?id=9"},
+			status:  http.StatusOK,
+			object:  "/admin?9",
+			action:  http.MethodGet,
+		},
+		{
+			name:    "prefix is not a path segment",
+			method:  http.MethodGet,
+			target:  "/authzv2/workloads?id=4",
+			headers: map[string]string{"X-User": "alice"},
+			status:  http.StatusOK,
+			object:  "/authzv2/workloads?4",
+			action:  http.MethodGet,
+		},
+		{
+			name:    "unauthenticated",
+			method:  http.MethodGet,
+			target:  "/authz/workloads",
+			headers: map[string]string{},
+			status:  http.StatusUnauthorized,
+		},
+		{
+			name:    "forbidden",
+			method:  http.MethodGet,
+			target:  "/authz/workloads",
+			headers: map[string]string{"X-User": "bob"},
+			status:  http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &principalChecker{allowed: "alice"}
+
+			app := fiber.New()
+			app.All("/*", NewForwardAuthHandler(ForwardAuthConfig{
+				Checker:           checker,
+				Proxy:             tt.proxy,
+				PrincipalResolver: &headerPrincipalResolver{},
+				ObjectResolver:    &pathObjectResolver{},
+				ActionResolver:    &methodActionResolver{},
+				PathPrefix:        "/authz",
+			}))
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, tt.status, resp.StatusCode)
+
+			if tt.status == http.StatusOK {
+				require.Equal(t, "alice", resp.Header.Get(HeaderXAuthzPrincipal))
+				require.Equal(t, tt.object, resp.Header.Get(HeaderXAuthzObject))
+				require.Equal(t, tt.action, resp.Header.Get(HeaderXAuthzAction))
+			}
+		})
+	}
+}
+
+type principalChecker struct {
+	allowed AuthzPrincipal
+}
+
+func (p *principalChecker) Allowed(_ context.Context, principal AuthzPrincipal, _ AuthzObject, _ AuthzAction) (bool, error) {
+	return principal == p.allowed, nil
+}
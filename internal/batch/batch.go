@@ -0,0 +1,70 @@
+package batch
+
+import (
+	"context"
+	"sync"
+)
+
+// Map calls fn for every item with at most concurrency calls in flight
+// and returns the results in the order of the items.
+// The first error cancels the remaining calls and is returned.
+func Map[T, R any](ctx context.Context, items []T, concurrency int, fn func(context.Context, T) (R, error)) ([]R, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]R, len(items))
+	sem := make(chan struct{}, concurrency)
+
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		err  error
+	)
+
+	for i, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			r, e := fn(ctx, item)
+			if e != nil {
+				once.Do(func() {
+					err = e
+					cancel()
+				})
+
+				return
+			}
+
+			results[i] = r
+		}()
+	}
+
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,238 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrLoadPanicked is returned to the waiters of a load that panicked.
+var ErrLoadPanicked = errors.New("cache: load panicked")
+
+// Key is the key of a cached check.
+type Key struct {
+	// Principal is the subject of the check.
+	Principal string
+	// Object is the object of the check.
+	Object string
+	// Action is the action of the check.
+	Action string
+	// Context distinguishes checks that depend on the attributes of the request.
+	Context string
+	// Store distinguishes checks of different stores and authorization models.
+	Store string
+}
+
+// Clock returns the current time.
+type Clock func() time.Time
+
+type entry[V any] struct {
+	key     Key
+	value   V
+	expires time.Time
+}
+
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// Cache is a LRU cache with per entry expiry and
+// de-duplication of concurrent loads for the same key.
+type Cache[V any] struct {
+	maxEntries int
+	clock      Clock
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[Key]*list.Element
+	calls map[Key]*call[V]
+	// gen is incremented by every invalidation,
+	// loads that started before are not cached.
+	gen uint64
+}
+
+// New returns a new cache. A maxEntries of zero means no limit.
+func New[V any](maxEntries int, clock Clock) *Cache[V] {
+	if clock == nil {
+		clock = time.Now
+	}
+
+	return &Cache[V]{
+		maxEntries: maxEntries,
+		clock:      clock,
+		ll:         list.New(),
+		items:      make(map[Key]*list.Element),
+		calls:      make(map[Key]*call[V]),
+	}
+}
+
+// Get returns the value for the key if it is present and not expired.
+func (c *Cache[V]) Get(key Key) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.get(key)
+}
+
+func (c *Cache[V]) get(key Key) (V, bool) {
+	var zero V
+
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	e := el.Value.(*entry[V])
+	if !c.clock().Before(e.expires) {
+		c.removeElement(el)
+		return zero, false
+	}
+
+	c.ll.MoveToFront(el)
+
+	return e.value, true
+}
+
+// Set adds the value for the key. A ttl of zero or less does not cache the value.
+func (c *Cache[V]) Set(key Key, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(key, value, ttl)
+}
+
+func (c *Cache[V]) set(key Key, value V, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	expires := c.clock().Add(ttl)
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		e := el.Value.(*entry[V])
+		e.value = value
+		e.expires = expires
+
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&entry[V]{key: key, value: value, expires: expires})
+
+	if c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// Do returns the cached value for the key or calls fn to load it.
+// Concurrent calls for the same key wait for the first call to finish
+// or until their own context is done.
+// The returned ttl of fn determines how long the value is cached,
+// errors are never cached. A load that is running while the key is
+// invalidated is not cached.
+func (c *Cache[V]) Do(ctx context.Context, key Key, fn func() (V, time.Duration, error)) (V, error) {
+	c.mu.Lock()
+
+	if v, ok := c.get(key); ok {
+		c.mu.Unlock()
+		return v, nil
+	}
+
+	if cl, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+
+		select {
+		case <-cl.done:
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		}
+
+		// the load failed because the context of the first call is done, not ours.
+		if isContextErr(cl.err) && ctx.Err() == nil {
+			return c.Do(ctx, key, fn)
+		}
+
+		return cl.value, cl.err
+	}
+
+	cl := &call[V]{done: make(chan struct{}), err: ErrLoadPanicked}
+	c.calls[key] = cl
+	gen := c.gen
+	c.mu.Unlock()
+
+	var ttl time.Duration
+
+	defer func() {
+		c.mu.Lock()
+		if cl.err == nil && c.gen == gen {
+			c.set(key, cl.value, ttl)
+		}
+
+		if c.calls[key] == cl {
+			delete(c.calls, key)
+		}
+		c.mu.Unlock()
+
+		close(cl.done)
+	}()
+
+	cl.value, ttl, cl.err = fn()
+
+	return cl.value, cl.err
+}
+
+// DeleteFunc removes all entries for which fn returns true.
+// Running loads for these keys are not cached.
+func (c *Cache[V]) DeleteFunc(fn func(Key) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+
+	for key, el := range c.items {
+		if fn(key) {
+			c.removeElement(el)
+		}
+	}
+
+	for key := range c.calls {
+		if fn(key) {
+			delete(c.calls, key)
+		}
+	}
+}
+
+// Flush removes all entries.
+// Running loads are not cached.
+func (c *Cache[V]) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+
+	c.ll.Init()
+	clear(c.items)
+	clear(c.calls)
+}
+
+// Len returns the number of entries, including expired ones that have not been evicted yet.
+func (c *Cache[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+func (c *Cache[V]) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*entry[V]).key)
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoPanic(t *testing.T) {
+	t.Parallel()
+
+	c := New[bool](0, nil)
+	key := Key{Principal: "alice", Object: "doc", Action: "read"}
+
+	require.Panics(t, func() {
+		_, _ = c.Do(context.Background(), key, func() (bool, time.Duration, error) {
+			panic("boom")
+		})
+	})
+
+	v, err := c.Do(context.Background(), key, func() (bool, time.Duration, error) {
+		return true, time.Minute, nil
+	})
+	require.NoError(t, err)
+	require.True(t, v)
+}
+
+func TestDoInvalidatedLoad(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		invalidate func(c *Cache[bool])
+	}{
+		{
+			name: "delete func",
+			invalidate: func(c *Cache[bool]) {
+				c.DeleteFunc(func(k Key) bool { return k.Principal == "alice" })
+			},
+		},
+		{
+			name:       "flush",
+			invalidate: func(c *Cache[bool]) { c.Flush() },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := New[bool](0, nil)
+			key := Key{Principal: "alice", Object: "doc", Action: "read"}
+
+			_, err := c.Do(context.Background(), key, func() (bool, time.Duration, error) {
+				tt.invalidate(c)
+				return true, time.Minute, nil
+			})
+			require.NoError(t, err)
+
+			_, ok := c.Get(key)
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestDoWaiterContext(t *testing.T) {
+	t.Parallel()
+
+	c := New[bool](0, nil)
+	key := Key{Principal: "alice", Object: "doc", Action: "read"}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	go func() {
+		_, _ = c.Do(context.Background(), key, func() (bool, time.Duration, error) {
+			close(started)
+			<-release
+
+			return true, time.Minute, nil
+		})
+	}()
+
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := c.Do(ctx, key, func() (bool, time.Duration, error) {
+		return false, 0, nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	close(release)
+}
+
+func TestDoFirstCallerCanceled(t *testing.T) {
+	t.Parallel()
+
+	c := New[bool](0, nil)
+	key := Key{Principal: "alice", Object: "doc", Action: "read"}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	go func() {
+		_, _ = c.Do(context.Background(), key, func() (bool, time.Duration, error) {
+			close(started)
+			<-release
+
+			return false, 0, context.Canceled
+		})
+	}()
+
+	<-started
+
+	done := make(chan struct{})
+
+	var (
+		v   bool
+		err error
+	)
+
+	go func() {
+		defer close(done)
+
+		v, err = c.Do(context.Background(), key, func() (bool, time.Duration, error) {
+			return true, time.Minute, nil
+		})
+	}()
+
+	// give the waiter time to join the running load.
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	<-done
+
+	require.NoError(t, err)
+	require.True(t, v)
+}
@@ -23,7 +23,7 @@ const (
 	KeyID            = "fake-key-id"
 	FakeIssuer       = "fake-issuer"
 	FakeAudience     = "fake-users"
-	PermissionsClaim = "perm"
+	PermissionsClaim = authz.PermissionsClaim
 )
 
 type FakeAuthenticator struct {
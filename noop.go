@@ -1,6 +1,9 @@
 package authz
 
-var _ AuthzChecker = (*noop)(nil)
+var (
+	_ AuthzChecker    = (*noop)(nil)
+	_ DecisionChecker = (*noop)(nil)
+)
 
 type noop struct {
 	Unimplemented
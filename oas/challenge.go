@@ -0,0 +1,57 @@
+package oas
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const challengeLocal = "oas_challenge"
+
+// Challenge is an authentication error with a WWW-Authenticate challenge, e.g. of RFC 6750.
+type Challenge interface {
+	error
+	// WWWAuthenticate returns the value of the WWW-Authenticate header.
+	WWWAuthenticate() string
+}
+
+// SetChallenge records the challenge of a failed security requirement of the request.
+func SetChallenge(c *fiber.Ctx, challenge Challenge) {
+	c.Locals(challengeLocal, challenge)
+}
+
+// GetChallenge returns the challenge of the failed security requirements of the request.
+func GetChallenge(c *fiber.Ctx) (Challenge, bool) {
+	challenge, ok := c.Locals(challengeLocal).(Challenge)
+
+	return challenge, ok
+}
+
+// ClearChallenge removes the challenge, e.g. if an alternative security requirement is met.
+func ClearChallenge(c *fiber.Ctx) {
+	c.Locals(challengeLocal, nil)
+}
+
+const statusLocal = "oas_status"
+
+// SetStatus records the status of a failed security requirement of the request,
+// e.g. 401 if the request has no identity. The authentication errors are
+// otherwise answered with 400 by the request validator.
+func SetStatus(c *fiber.Ctx, status int) {
+	c.Locals(statusLocal, status)
+}
+
+// GetStatus returns the status of the failed security requirements of the request.
+func GetStatus(c *fiber.Ctx) (int, bool) {
+	status, ok := c.Locals(statusLocal).(int)
+
+	return status, ok
+}
+
+// SetErrorStatus records the status of the error if it is a *fiber.Error.
+func SetErrorStatus(c *fiber.Ctx, err error) {
+	var e *fiber.Error
+	if errors.As(err, &e) {
+		SetStatus(c, e.Code)
+	}
+}
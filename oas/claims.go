@@ -0,0 +1,139 @@
+package oas
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultClaimPath is the default path of the claims, the scopes of OAuth 2.0.
+const DefaultClaimPath = "scope"
+
+// DefaultClaimDelimiter is the default delimiter of claims in a string.
+const DefaultClaimDelimiter = " "
+
+// ClaimPathSeparator separates the keys of a nested claim path, e.g. realm_access.roles.
+const ClaimPathSeparator = "."
+
+// ClaimsOpts are the options for extracting claims.
+type ClaimsOpts struct {
+	// Paths are the paths of the claims that are merged.
+	Paths []string
+	// Delimiter splits claims that are a string.
+	Delimiter string
+}
+
+// Configure sets the configuration for extracting claims.
+func (o *ClaimsOpts) Configure(opts ...ClaimsOpt) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// ClaimsOpt is a function that sets an option for extracting claims.
+type ClaimsOpt func(*ClaimsOpts)
+
+// DefaultClaimsOpts returns the default options, the space-delimited scope claim.
+func DefaultClaimsOpts() ClaimsOpts {
+	return ClaimsOpts{
+		Paths:     []string{DefaultClaimPath},
+		Delimiter: DefaultClaimDelimiter,
+	}
+}
+
+// WithClaimPaths sets the paths of the claims, e.g. scope, roles,
+// realm_access.roles or resource_access.<client>.roles.
+// The paths replace the default paths, see WithAdditionalClaimPaths.
+func WithClaimPaths(paths ...string) ClaimsOpt {
+	return func(o *ClaimsOpts) {
+		o.Paths = paths
+	}
+}
+
+// WithAdditionalClaimPaths adds paths of claims to the default paths.
+func WithAdditionalClaimPaths(paths ...string) ClaimsOpt {
+	return func(o *ClaimsOpts) {
+		o.Paths = append(append([]string{}, o.Paths...), paths...)
+	}
+}
+
+// WithClaimDelimiter sets the delimiter of claims that are a string.
+func WithClaimDelimiter(delimiter string) ClaimsOpt {
+	return func(o *ClaimsOpts) {
+		o.Delimiter = delimiter
+	}
+}
+
+// ExtractClaims extracts and merges the claims of all paths.
+// A claim is a string that is split by the delimiter or an array of strings.
+// Missing claims are skipped and duplicates are removed.
+func ExtractClaims(claims map[string]interface{}, opts ...ClaimsOpt) ([]string, error) {
+	options := DefaultClaimsOpts()
+	options.Configure(opts...)
+
+	values := []string{}
+	seen := map[string]bool{}
+
+	add := func(s string) {
+		if s != "" && !seen[s] {
+			seen[s] = true
+			values = append(values, s)
+		}
+	}
+
+	for _, path := range options.Paths {
+		v, ok := ClaimValue(claims, path)
+		if !ok {
+			continue
+		}
+
+		switch v := v.(type) {
+		case string:
+			for _, s := range strings.Split(v, options.Delimiter) {
+				add(strings.TrimSpace(s))
+			}
+		case []string:
+			for _, s := range v {
+				add(s)
+			}
+		case []interface{}:
+			for i, e := range v {
+				s, ok := e.(string)
+				if !ok {
+					return nil, fmt.Errorf("%s[%d] is not a string", path, i)
+				}
+
+				add(s)
+			}
+		default:
+			return nil, fmt.Errorf("'%s' claim is unexpected type", path)
+		}
+	}
+
+	return values, nil
+}
+
+// ClaimValue returns the value of the claim at the nested path.
+// Keys that contain the separator are matched as is before the path is split,
+// e.g. the namespaced claim https://example.com/roles or realm_access.roles.
+func ClaimValue(claims map[string]interface{}, path string) (interface{}, bool) {
+	if v, ok := claims[path]; ok {
+		return v, true
+	}
+
+	for i := 0; i < len(path); i++ {
+		if !strings.HasPrefix(path[i:], ClaimPathSeparator) {
+			continue
+		}
+
+		m, ok := claims[path[:i]].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if v, ok := ClaimValue(m, path[i+len(ClaimPathSeparator):]); ok {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,113 @@
+package oas_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeiss/fiber-authz/oas"
+)
+
+func TestExtractClaims(t *testing.T) {
+	t.Parallel()
+
+	claims := map[string]interface{}{
+		"scope": "read:teams write:teams",
+		"roles": []interface{}{"admin", "read:teams"},
+		"realm_access": map[string]interface{}{
+			"roles": []interface{}{"offline_access"},
+		},
+		"resource_access": map[string]interface{}{
+			"api": map[string]interface{}{"roles": []interface{}{"editor"}},
+		},
+		"https://example.com/roles": []interface{}{"auditor"},
+		"https://example.com/app": map[string]interface{}{
+			"roles": []interface{}{"owner"},
+		},
+		"groups": "a,b",
+		"spaced": "  read   write ",
+		"mixed":  []interface{}{"admin", float64(1)},
+		"exp":    float64(1),
+	}
+
+	tests := []struct {
+		name   string
+		opts   []oas.ClaimsOpt
+		values []string
+		err    bool
+	}{
+		{
+			name:   "scope",
+			values: []string{"read:teams", "write:teams"},
+		},
+		{
+			name:   "merged",
+			opts:   []oas.ClaimsOpt{oas.WithClaimPaths("scope", "roles")},
+			values: []string{"read:teams", "write:teams", "admin"},
+		},
+		{
+			name:   "keycloak",
+			opts:   []oas.ClaimsOpt{oas.WithClaimPaths("realm_access.roles", "resource_access.api.roles")},
+			values: []string{"offline_access", "editor"},
+		},
+		{
+			name:   "namespaced",
+			opts:   []oas.ClaimsOpt{oas.WithClaimPaths("https://example.com/roles")},
+			values: []string{"auditor"},
+		},
+		{
+			name:   "nested in namespaced",
+			opts:   []oas.ClaimsOpt{oas.WithClaimPaths("https://example.com/app.roles")},
+			values: []string{"owner"},
+		},
+		{
+			name:   "delimiter",
+			opts:   []oas.ClaimsOpt{oas.WithClaimPaths("groups"), oas.WithClaimDelimiter(",")},
+			values: []string{"a", "b"},
+		},
+		{
+			name:   "default delimiter",
+			opts:   []oas.ClaimsOpt{oas.WithClaimPaths("spaced")},
+			values: []string{"read", "write"},
+		},
+		{
+			name:   "additional",
+			opts:   []oas.ClaimsOpt{oas.WithAdditionalClaimPaths("roles")},
+			values: []string{"read:teams", "write:teams", "admin"},
+		},
+		{
+			name:   "missing",
+			opts:   []oas.ClaimsOpt{oas.WithClaimPaths("perms", "scope.nested")},
+			values: []string{},
+		},
+		{
+			name:   "missing nested",
+			opts:   []oas.ClaimsOpt{oas.WithClaimPaths("realm_access.groups", "resource_access.web.roles", "resource_access.api.roles.admin")},
+			values: []string{},
+		},
+		{
+			name: "array with non-strings",
+			opts: []oas.ClaimsOpt{oas.WithClaimPaths("mixed")},
+			err:  true,
+		},
+		{
+			name: "unexpected type",
+			opts: []oas.ClaimsOpt{oas.WithClaimPaths("exp")},
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			values, err := oas.ExtractClaims(claims, tt.opts...)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.values, values)
+		})
+	}
+}
@@ -40,6 +40,8 @@ func (n NoopAuthenticator) Close() {}
 type AuthClaims struct {
 	Subject string
 	Scopes  map[string]bool
+	// Claims are all claims of the token.
+	Claims map[string]interface{}
 }
 
 // OidcConfig contains authorization server metadata. See https://datatracker.ietf.org/doc/html/rfc8414#section-2
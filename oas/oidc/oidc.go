@@ -57,6 +57,8 @@ type RemoteOidcOpts struct {
 	IssuerAliases []string
 	Audience      string
 	Client        *http.Client
+	// ScopeClaims are the options for extracting the scopes from the claims.
+	ScopeClaims []oas.ClaimsOpt
 }
 
 // RemoteOidcOpt is the options for creating a new RemoteOidcValidator.
@@ -100,6 +102,14 @@ func WithAudience(audience string) RemoteOidcOpt {
 	}
 }
 
+// WithScopeClaims sets the options for extracting the scopes from the claims,
+// e.g. oas.WithClaimPaths("realm_access.roles", "resource_access.api.roles") for Keycloak.
+func WithScopeClaims(opts ...oas.ClaimsOpt) RemoteOidcOpt {
+	return func(o *RemoteOidcOpts) {
+		o.ScopeClaims = opts
+	}
+}
+
 // WithClient sets the client for the RemoteOidcValidator.
 func WithClient(client *http.Client) RemoteOidcOpt {
 	return func(o *RemoteOidcOpts) {
@@ -188,6 +198,9 @@ func (oidc *RemoteOidcValidator) GetConfiguration(ctx context.Context) (*oas.Oid
 }
 
 // Authenticate returns a nil error and the AuthClaims info (if available) if the subject is authenticated or a
+// non-nil error with an appropriate error cause otherwise.
+// The token must have all scopes of the security requirement, otherwise an InsufficientScopeError is returned.
+// As OpenAPI security requirements are alternatives, the request is allowed if one of them is met.
 func Authenticate(v Validator) openapi3filter.AuthenticationFunc {
 	return func(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
 		c := middleware.GetFiberContext(ctx)
@@ -197,9 +210,15 @@ func Authenticate(v Validator) openapi3filter.AuthenticationFunc {
 			return err
 		}
 
-		usrCtx := context.WithValue(c.UserContext(), jwtToken, principal)
+		if missing := MissingScopes(principal, input.Scopes); len(missing) > 0 {
+			return addInsufficientScope(c, missing)
+		}
+
+		// the missing scopes of failed alternatives do not apply anymore.
+		oas.ClearChallenge(c)
+
 		// nolint:contextcheck
-		c.SetUserContext(usrCtx)
+		c.SetUserContext(WithJWT(c.UserContext(), principal))
 
 		return nil
 	}
@@ -264,16 +283,17 @@ func (oidc *RemoteOidcValidator) Validate(req *http.Request) (*oas.AuthClaims, e
 	principal := &oas.AuthClaims{
 		Subject: subject,
 		Scopes:  make(map[string]bool),
+		Claims:  claims,
 	}
 
 	// optional scopes
-	if scopeKey, ok := claims["scope"]; ok {
-		if scope, ok := scopeKey.(string); ok {
-			scopes := strings.Split(scope, " ")
-			for _, s := range scopes {
-				principal.Scopes[s] = true
-			}
-		}
+	scopes, err := oas.ExtractClaims(claims, oidc.Opts.ScopeClaims...)
+	if err != nil {
+		return nil, ErrClaimsInvalid
+	}
+
+	for _, s := range scopes {
+		principal.Scopes[s] = true
 	}
 
 	return principal, nil
@@ -300,6 +320,11 @@ func GetJWSFromRequest(req *http.Request) (string, error) {
 	return strings.TrimPrefix(authHdr, prefix), nil
 }
 
+// WithJWT returns a new context with the JWT token.
+func WithJWT(ctx context.Context, principal *oas.AuthClaims) context.Context {
+	return context.WithValue(ctx, jwtToken, principal)
+}
+
 // GetJWTFromContext extracts the JWT token from the context.
 func GetJWTFromContext(ctx context.Context) (*oas.AuthClaims, bool) {
 	principal, ok := ctx.Value(jwtToken).(*oas.AuthClaims)
@@ -0,0 +1,90 @@
+package oidc
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zeiss/fiber-authz/oas"
+)
+
+var _ oas.Challenge = (*InsufficientScopeError)(nil)
+
+// InsufficientScopeError is returned when the token has not all scopes of a security requirement.
+// It unwraps to fiber.ErrForbidden.
+type InsufficientScopeError struct {
+	// Missing are the missing scopes.
+	Missing []string
+}
+
+// Error returns the error message.
+func (e *InsufficientScopeError) Error() string {
+	return fmt.Sprintf("insufficient scope: missing %s", strings.Join(e.Missing, " "))
+}
+
+// Unwrap returns fiber.ErrForbidden.
+func (e *InsufficientScopeError) Unwrap() error {
+	return fiber.ErrForbidden
+}
+
+// WWWAuthenticate returns the WWW-Authenticate challenge of RFC 6750.
+func (e *InsufficientScopeError) WWWAuthenticate() string {
+	return fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, strings.Join(e.Missing, " "))
+}
+
+// MissingScopes returns the scopes that are required but not granted.
+func MissingScopes(principal *oas.AuthClaims, required []string) []string {
+	missing := []string{}
+
+	for _, s := range required {
+		if !principal.Scopes[s] {
+			missing = append(missing, s)
+		}
+	}
+
+	return missing
+}
+
+// GetInsufficientScope returns the missing scopes of the failed security requirements of the request.
+func GetInsufficientScope(c *fiber.Ctx) (*InsufficientScopeError, bool) {
+	challenge, ok := oas.GetChallenge(c)
+	if !ok {
+		return nil, false
+	}
+
+	err, ok := challenge.(*InsufficientScopeError)
+
+	return err, ok
+}
+
+// SetInsufficientScopeHeader sets the WWW-Authenticate header if a security requirement failed
+// because of missing scopes. It returns true if the header has been set.
+func SetInsufficientScopeHeader(c *fiber.Ctx) bool {
+	err, ok := GetInsufficientScope(c)
+	if !ok {
+		return false
+	}
+
+	c.Set(fiber.HeaderWWWAuthenticate, err.WWWAuthenticate())
+
+	return true
+}
+
+// addInsufficientScope records the missing scopes of a failed security requirement,
+// as the requirements are alternatives the scopes of all of them are listed.
+func addInsufficientScope(c *fiber.Ctx, missing []string) *InsufficientScopeError {
+	err, ok := GetInsufficientScope(c)
+	if !ok {
+		err = &InsufficientScopeError{}
+		oas.SetChallenge(c, err)
+	}
+
+	for _, s := range missing {
+		if !slices.Contains(err.Missing, s) {
+			err.Missing = append(err.Missing, s)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,142 @@
+package oidc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gofiber/fiber/v2"
+	middleware "github.com/oapi-codegen/fiber-middleware"
+	"github.com/stretchr/testify/require"
+	authz "github.com/zeiss/fiber-authz"
+	"github.com/zeiss/fiber-authz/oas"
+	"github.com/zeiss/fiber-authz/oas/oidc"
+)
+
+const scopeSpec = `
+openapi: 3.0.0
+info:
+  title: test
+  version: 1.0.0
+components:
+  securitySchemes:
+    bearer:
+      type: http
+      scheme: bearer
+paths:
+  /teams:
+    get:
+      security:
+        - bearer: [read:teams, list:teams]
+        - bearer: [admin]
+      responses:
+        "200":
+          description: ok
+  /search:
+    get:
+      security:
+        - bearer: [admin]
+        - bearer: [read]
+      parameters:
+        - name: q
+          in: query
+          required: true
+          schema:
+            type: string
+      responses:
+        "200":
+          description: ok
+`
+
+type scopeValidator struct{}
+
+func (scopeValidator) Validate(req *http.Request) (*oas.AuthClaims, error) {
+	jws, err := oidc.GetJWSFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &oas.AuthClaims{Subject: "alice", Scopes: map[string]bool{}}
+	for _, s := range strings.Fields(jws) {
+		claims.Scopes[s] = true
+	}
+
+	return claims, nil
+}
+
+func TestAuthenticateScopes(t *testing.T) {
+	t.Parallel()
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(scopeSpec))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		path      string
+		scopes    string
+		status    int
+		challenge string
+	}{
+		{
+			name:   "all scopes of a requirement",
+			scopes: "read:teams list:teams",
+			status: fiber.StatusOK,
+		},
+		{
+			name:   "alternative requirement",
+			scopes: "admin",
+			status: fiber.StatusOK,
+		},
+		{
+			name:      "insufficient scope",
+			scopes:    "read:teams",
+			status:    fiber.StatusForbidden,
+			challenge: `Bearer error="insufficient_scope", scope="list:teams admin"`,
+		},
+		{
+			name:   "alternative requirement with invalid parameters",
+			path:   "/search",
+			scopes: "read",
+			status: fiber.StatusBadRequest,
+		},
+		{
+			name:   "alternative requirement with parameters",
+			path:   "/search?q=a",
+			scopes: "read",
+			status: fiber.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := fiber.New()
+			app.Use(middleware.OapiRequestValidatorWithOptions(doc, &middleware.Options{
+				Options: openapi3filter.Options{
+					AuthenticationFunc: oidc.Authenticate(scopeValidator{}),
+				},
+				ErrorHandler: authz.NewOpenAPIErrorHandler(),
+			}))
+			app.Get("/*", func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			path := tt.path
+			if path == "" {
+				path = "/teams"
+			}
+
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+tt.scopes)
+
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, tt.status, res.StatusCode)
+			require.Equal(t, tt.challenge, res.Header.Get(fiber.HeaderWWWAuthenticate))
+		})
+	}
+}
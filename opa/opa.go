@@ -0,0 +1,309 @@
+package opa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/open-policy-agent/opa/v1/loader"
+	"github.com/open-policy-agent/opa/v1/rego"
+	authz "github.com/zeiss/fiber-authz"
+)
+
+// DefaultQuery is the default query that is evaluated.
+// The result can either be a boolean or an object with an allow field
+// and optional reason and obligations fields.
+const DefaultQuery = "data.authz"
+
+// ErrNoPolicies is returned when no policy source is configured.
+var ErrNoPolicies = errors.New("opa: no policies configured")
+
+// ErrNoDir is returned when watching without a directory.
+var ErrNoDir = errors.New("opa: no directory to watch")
+
+var (
+	_ authz.AuthzChecker    = (*Checker)(nil)
+	_ authz.DecisionChecker = (*Checker)(nil)
+)
+
+// Opts are the options for the checker.
+type Opts struct {
+	// Query is the query that is evaluated.
+	Query string
+	// FS is the file system with the policies and data.
+	FS fs.FS
+	// Dir is the directory with the policies and data.
+	// It is used to watch for changes.
+	Dir string
+	// Debounce is the time to wait for further changes before reloading.
+	Debounce time.Duration
+}
+
+// Configure sets the configuration for the checker.
+func (o *Opts) Configure(opts ...Opt) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// Opt is a function that sets an option on the checker.
+type Opt func(*Opts)
+
+// DefaultOpts returns the default options.
+func DefaultOpts() Opts {
+	return Opts{
+		Query:    DefaultQuery,
+		Debounce: 100 * time.Millisecond,
+	}
+}
+
+// WithQuery sets the query that is evaluated.
+func WithQuery(query string) Opt {
+	return func(o *Opts) {
+		o.Query = query
+	}
+}
+
+// WithFS loads the policies from a file system, e.g. an embed.FS.
+func WithFS(fsys fs.FS) Opt {
+	return func(o *Opts) {
+		o.FS = fsys
+	}
+}
+
+// WithDir loads the policies from a directory.
+func WithDir(dir string) Opt {
+	return func(o *Opts) {
+		o.Dir = dir
+		o.FS = os.DirFS(dir)
+	}
+}
+
+// WithDebounce sets the time to wait for further changes before reloading.
+func WithDebounce(d time.Duration) Opt {
+	return func(o *Opts) {
+		o.Debounce = d
+	}
+}
+
+// Checker is an authz checker that evaluates Rego policies with the embedded OPA.
+type Checker struct {
+	opts Opts
+
+	mu    sync.RWMutex
+	query rego.PreparedEvalQuery
+}
+
+// NewChecker returns a new checker and loads the policies.
+func NewChecker(ctx context.Context, opts ...Opt) (*Checker, error) {
+	options := DefaultOpts()
+	options.Configure(opts...)
+
+	if options.FS == nil {
+		return nil, ErrNoPolicies
+	}
+
+	c := &Checker{opts: options}
+
+	if err := c.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Reload loads the policies and data and prepares the query.
+// The previous policies stay active if loading fails.
+func (c *Checker) Reload(ctx context.Context) error {
+	result, err := loader.NewFileLoader().WithFS(c.opts.FS).All([]string{"."})
+	if err != nil {
+		return fmt.Errorf("opa: loading policies: %w", err)
+	}
+
+	compiler, err := result.Compiler()
+	if err != nil {
+		return fmt.Errorf("opa: compiling policies: %w", err)
+	}
+
+	store, err := result.Store()
+	if err != nil {
+		return fmt.Errorf("opa: loading data: %w", err)
+	}
+
+	query, err := rego.New(
+		rego.Query(c.opts.Query),
+		rego.Compiler(compiler),
+		rego.Store(store),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return fmt.Errorf("opa: preparing query: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.query = query
+
+	return nil
+}
+
+// Watch reloads the policies when files in the directory change.
+// It blocks until the context is done. Reload errors are passed to onError if not nil.
+func (c *Checker) Watch(ctx context.Context, onError func(error)) error {
+	if c.opts.Dir == "" {
+		return ErrNoDir
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	err = filepath.WalkDir(c.opts.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return watcher.Add(path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var reload <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					_ = watcher.Add(event.Name)
+				}
+			}
+
+			reload = time.After(c.opts.Debounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			if onError != nil {
+				onError(err)
+			}
+		case <-reload:
+			reload = nil
+
+			if err := c.Reload(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Allowed returns true if the policies allow the principal to perform the action on the object.
+func (c *Checker) Allowed(ctx context.Context, principal authz.AuthzPrincipal, object authz.AuthzObject, action authz.AuthzAction) (bool, error) {
+	decision, err := c.Decide(ctx, principal, object, action)
+	if err != nil {
+		return false, err
+	}
+
+	return decision.Allowed, nil
+}
+
+// Decide evaluates the query with the principal, object, action and the
+// request attributes from the context as input.
+func (c *Checker) Decide(ctx context.Context, principal authz.AuthzPrincipal, object authz.AuthzObject, action authz.AuthzAction) (authz.Decision, error) {
+	start := time.Now()
+
+	input := map[string]interface{}{
+		"principal":  principal.String(),
+		"object":     object.String(),
+		"action":     action.String(),
+		"attributes": map[string]interface{}(authz.GetAttributes(ctx)),
+	}
+
+	c.mu.RLock()
+	query := c.query
+	c.mu.RUnlock()
+
+	rs, err := query.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return authz.Decision{}, err
+	}
+
+	decision := authz.Deny("opa", "undefined")
+	if len(rs) > 0 && len(rs[0].Expressions) > 0 {
+		decision = parseDecision(rs[0].Expressions[0].Value)
+	}
+
+	decision.Policy = c.opts.Query
+	decision.Duration = time.Since(start)
+
+	return decision, nil
+}
+
+func parseDecision(v interface{}) authz.Decision {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return authz.Allow("opa", "")
+		}
+
+		return authz.Deny("opa", "")
+	case map[string]interface{}:
+		decision := authz.Deny("opa", "")
+
+		for _, key := range []string{"allow", "allowed"} {
+			if allowed, ok := v[key].(bool); ok {
+				decision.Allowed = allowed
+				break
+			}
+		}
+
+		decision.Reason = parseReason(v["reason"])
+
+		if obligations, ok := v["obligations"].(map[string]interface{}); ok {
+			decision.Obligations = obligations
+		}
+
+		return decision
+	default:
+		return authz.Deny("opa", fmt.Sprintf("unexpected result type %T", v))
+	}
+}
+
+func parseReason(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []interface{}:
+		reasons := make([]string, 0, len(v))
+		for _, r := range v {
+			if s, ok := r.(string); ok {
+				reasons = append(reasons, s)
+			}
+		}
+
+		return strings.Join(reasons, "; ")
+	default:
+		return ""
+	}
+}
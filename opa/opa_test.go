@@ -0,0 +1,129 @@
+package opa_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	authz "github.com/zeiss/fiber-authz"
+	"github.com/zeiss/fiber-authz/opa"
+)
+
+const policy = `package authz
+
+default allow := false
+
+allow if {
+	input.principal == "user:alice"
+	input.action == data.actions[_]
+}
+
+allow if {
+	input.attributes.context.ip == "10.0.0.1"
+}
+
+reason contains "alice only" if not allow
+`
+
+const data = `{"actions": ["read", "write"]}`
+
+func TestChecker(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"authz/policy.rego": &fstest.MapFile{Data: []byte(policy)},
+		"data.json":         &fstest.MapFile{Data: []byte(data)},
+	}
+
+	checker, err := opa.NewChecker(context.Background(), opa.WithFS(fsys))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		principal authz.AuthzPrincipal
+		action    authz.AuthzAction
+		allowed   bool
+		reason    string
+	}{
+		{
+			name:      "allowed",
+			ctx:       context.Background(),
+			principal: "user:alice",
+			action:    "read",
+			allowed:   true,
+		},
+		{
+			name:      "denied action",
+			ctx:       context.Background(),
+			principal: "user:alice",
+			action:    "delete",
+			reason:    "alice only",
+		},
+		{
+			name:      "denied principal",
+			ctx:       context.Background(),
+			principal: "user:bob",
+			action:    "read",
+			reason:    "alice only",
+		},
+		{
+			name:      "allowed by attributes",
+			ctx:       authz.WithAttributes(context.Background(), authz.Attributes{"context": map[string]interface{}{"ip": "10.0.0.1"}}),
+			principal: "user:bob",
+			action:    "read",
+			allowed:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := checker.Decide(tt.ctx, tt.principal, "document:1", tt.action)
+			require.NoError(t, err)
+			require.Equal(t, tt.allowed, decision.Allowed)
+			require.Equal(t, tt.reason, decision.Reason)
+			require.Equal(t, "opa", decision.Checker)
+		})
+	}
+}
+
+func TestCheckerCompileError(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"policy.rego": &fstest.MapFile{Data: []byte("package authz\n\nallow if {")},
+	}
+
+	_, err := opa.NewChecker(context.Background(), opa.WithFS(fsys))
+	require.Error(t, err)
+}
+
+func TestCheckerWatch(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "policy.rego")
+
+	require.NoError(t, os.WriteFile(file, []byte("package authz\n\nallow := false\n"), 0o600))
+
+	checker, err := opa.NewChecker(context.Background(), opa.WithDir(dir), opa.WithDebounce(10*time.Millisecond))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		_ = checker.Watch(ctx, nil)
+	}()
+
+	require.Eventually(t, func() bool {
+		_ = os.WriteFile(file, []byte("package authz\n\nallow := true\n"), 0o600)
+
+		allowed, err := checker.Allowed(context.Background(), "user:alice", "document:1", "read")
+		return err == nil && allowed
+	}, 5*time.Second, 50*time.Millisecond)
+}
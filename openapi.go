@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3filter"
 	"github.com/gofiber/fiber/v2"
 	middleware "github.com/oapi-codegen/fiber-middleware"
+	"github.com/zeiss/fiber-authz/oas"
 )
 
 // ErrNoAuthzContext is the error returned when the context is not found.
@@ -39,10 +41,11 @@ func DefaultAuthzExtractor(c *fiber.Ctx) (AuthzPrincipal, AuthzObject, AuthzActi
 
 // OpenAPIAuthenticatorOpts are the OpenAPI authenticator options.
 type OpenAPIAuthenticatorOpts struct {
-	AuthzPrincipalResolver AuthzPrincipalResolver
-	AuthzObjectResolver    AuthzObjectResolver
-	AuthzActionResolver    AuthzActionResolver
-	AuthzChecker           AuthzChecker
+	AuthzPrincipalResolver  AuthzPrincipalResolver
+	AuthzObjectResolver     AuthzObjectResolver
+	AuthzActionResolver     AuthzActionResolver
+	AuthzAttributesResolver AuthzAttributesResolver
+	AuthzChecker            AuthzChecker
 }
 
 // Conigure the OpenAPI authenticator.
@@ -58,10 +61,11 @@ type OpenAPIAuthenticatorOpt func(*OpenAPIAuthenticatorOpts)
 // OpenAPIAuthenticatorDefaultOpts are the default OpenAPI authenticator options.
 func OpenAPIAuthenticatorDefaultOpts() OpenAPIAuthenticatorOpts {
 	return OpenAPIAuthenticatorOpts{
-		AuthzChecker:           NewNoop(),
-		AuthzPrincipalResolver: NewNoopPrincipalResolver(),
-		AuthzObjectResolver:    NewNoopObjectResolver(),
-		AuthzActionResolver:    NewNoopActionResolver(),
+		AuthzChecker:            NewNoop(),
+		AuthzPrincipalResolver:  NewNoopPrincipalResolver(),
+		AuthzObjectResolver:     NewNoopObjectResolver(),
+		AuthzActionResolver:     NewNoopActionResolver(),
+		AuthzAttributesResolver: NewRequestAttributesResolver(),
 	}
 }
 
@@ -86,6 +90,13 @@ func WithAuthzActionResolver(resolver AuthzActionResolver) OpenAPIAuthenticatorO
 	}
 }
 
+// WithAuthzAttributesResolver sets the attributes resolver.
+func WithAuthzAttributesResolver(resolver AuthzAttributesResolver) OpenAPIAuthenticatorOpt {
+	return func(opts *OpenAPIAuthenticatorOpts) {
+		opts.AuthzAttributesResolver = resolver
+	}
+}
+
 // WithAuthzChecker sets the authz checker.
 func WithAuthzChecker(checker AuthzChecker) OpenAPIAuthenticatorOpt {
 	return func(opts *OpenAPIAuthenticatorOpts) {
@@ -93,9 +104,19 @@ func WithAuthzChecker(checker AuthzChecker) OpenAPIAuthenticatorOpt {
 	}
 }
 
+// securityRequirementsError is the prefix of the message of failed security requirements.
+const securityRequirementsError = "error in openapi3filter.SecurityRequirementsError"
+
 // NewOpenAPIErrorHandler creates a new OpenAPI error handler.
+// Requests that fail the security requirements with a challenge, e.g. because of missing scopes,
+// are answered with the status and the WWW-Authenticate header of the challenge.
+// Otherwise the status that has been recorded with oas.SetStatus is used, e.g. 401 without identity.
 func NewOpenAPIErrorHandler() middleware.ErrorHandler {
 	return func(c *fiber.Ctx, message string, statusCode int) {
+		if strings.HasPrefix(message, securityRequirementsError) {
+			statusCode = securityRequirementsStatus(c, statusCode)
+		}
+
 		c.Status(statusCode).JSON(map[string]interface{}{
 			"message": message,
 			"code":    statusCode,
@@ -103,6 +124,27 @@ func NewOpenAPIErrorHandler() middleware.ErrorHandler {
 	}
 }
 
+// securityRequirementsStatus returns the status of the failed security requirements
+// and sets the WWW-Authenticate header of the challenge.
+func securityRequirementsStatus(c *fiber.Ctx, statusCode int) int {
+	if challenge, ok := oas.GetChallenge(c); ok {
+		c.Set(fiber.HeaderWWWAuthenticate, challenge.WWWAuthenticate())
+
+		var e *fiber.Error
+		if errors.As(challenge, &e) {
+			return e.Code
+		}
+
+		return statusCode
+	}
+
+	if status, ok := oas.GetStatus(c); ok {
+		return status
+	}
+
+	return statusCode
+}
+
 // NewOpenAPIAuthenticator creates a new OpenAPI authenticator.
 func NewOpenAPIAuthenticator(opts ...OpenAPIAuthenticatorOpt) openapi3filter.AuthenticationFunc {
 	return func(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
@@ -126,23 +168,25 @@ func NewOpenAPIAuthenticator(opts ...OpenAPIAuthenticatorOpt) openapi3filter.Aut
 			return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("error resolving action: %w", err).Error())
 		}
 
-		allowed, err := options.AuthzChecker.Allowed(ctx, principal, object, action)
+		attrs, err := options.AuthzAttributesResolver.Resolve(c)
 		if err != nil {
-			return fiber.NewError(fiber.StatusInternalServerError, "internal server error")
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("error resolving attributes: %w", err).Error())
 		}
 
-		if !allowed {
-			return fiber.NewError(fiber.StatusForbidden, "forbidden")
+		decision, err := Decide(WithAttributes(ctx, attrs), options.AuthzChecker, principal, object, action)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "internal server error")
 		}
 
-		// Create a new context for the authz context.
-		usrCtx := c.UserContext()
-
+		// Create a new context for the authz context and decision.
 		authzCtx := NewAuthzContext(principal, object, action)
-		authCtx := context.WithValue(usrCtx, authzContext, authzCtx)
 
 		// nolint: contextcheck
-		c.SetUserContext(authCtx)
+		c.SetUserContext(WithAuthzDecision(c.UserContext(), authzCtx, decision))
+
+		if !decision.Allowed {
+			return fiber.NewError(fiber.StatusForbidden, "forbidden")
+		}
 
 		return nil
 	}
@@ -0,0 +1,121 @@
+package openfga
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/openfga/go-sdk/client"
+	"github.com/zeiss/fiber-authz/internal/batch"
+)
+
+// DefaultBatchConcurrency is the default number of concurrent checks of the fallback batch checker.
+const DefaultBatchConcurrency = 10
+
+// Check is a single check of a batch.
+type Check struct {
+	// User is the user of the check.
+	User User `json:"user"`
+	// Relation is the relation of the check.
+	Relation Relation `json:"relation"`
+	// Object is the object of the check.
+	Object Object `json:"object"`
+	// ContextualTuples are sent in addition to the contextual tuples of the context.
+	ContextualTuples []Tuple `json:"contextual_tuples,omitempty"`
+	// Context is merged with the condition context of the context.
+	Context map[string]interface{} `json:"context,omitempty"`
+}
+
+// BatchChecker is an interface for checking multiple permissions at once.
+type BatchChecker interface {
+	// BatchAllowed returns the result of every check in the order of the checks.
+	BatchAllowed(ctx context.Context, checks []Check) ([]bool, error)
+}
+
+// BatchAllowed returns the result of every check in the order of the checks.
+// If the checker does not implement the BatchChecker interface,
+// the checks are fanned out with the DefaultBatchConcurrency.
+func BatchAllowed(ctx context.Context, checker Checker, checks []Check) ([]bool, error) {
+	if bc, ok := checker.(BatchChecker); ok {
+		return bc.BatchAllowed(ctx, checks)
+	}
+
+	return NewBatch(checker, DefaultBatchConcurrency).BatchAllowed(ctx, checks)
+}
+
+var _ BatchChecker = (*Batch)(nil)
+
+// Batch is a batch checker that fans out the checks to a checker.
+type Batch struct {
+	checker     Checker
+	concurrency int
+}
+
+// NewBatch returns a new batch checker with bounded concurrency.
+func NewBatch(checker Checker, concurrency int) *Batch {
+	return &Batch{checker: checker, concurrency: concurrency}
+}
+
+// BatchAllowed returns the result of every check in the order of the checks.
+func (b *Batch) BatchAllowed(ctx context.Context, checks []Check) ([]bool, error) {
+	return batch.Map(ctx, checks, b.concurrency, func(ctx context.Context, check Check) (bool, error) {
+		return b.checker.Allowed(withCheckContext(ctx, check), check.User, check.Relation, check.Object)
+	})
+}
+
+var _ BatchChecker = (*ClientImpl)(nil)
+
+// BatchAllowed returns the result of every check using the OpenFGA BatchCheck API.
+// Checks that fail on the server are denied and the error is logged.
+func (c *ClientImpl) BatchAllowed(ctx context.Context, checks []Check) ([]bool, error) {
+	if len(checks) == 0 {
+		return []bool{}, nil
+	}
+
+	body := client.ClientBatchCheckRequest{
+		Checks: make([]client.ClientBatchCheckItem, len(checks)),
+	}
+
+	for i, check := range checks {
+		checkCtx := withCheckContext(ctx, check)
+
+		body.Checks[i] = client.ClientBatchCheckItem{
+			User:             EntityString(check.User),
+			Relation:         EntityString(check.Relation),
+			Object:           EntityString(check.Object),
+			CorrelationId:    strconv.Itoa(i),
+			ContextualTuples: ClientContextualTuples(GetContextualTuples(checkCtx)),
+			Context:          ClientConditionContext(GetConditionContext(checkCtx)),
+		}
+	}
+
+	opts, err := c.opts.BatchCheckRequestOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.client.BatchCheck(ctx).Body(body).Options(opts).Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	results := res.GetResult()
+	allowed := make([]bool, len(checks))
+
+	for i := range checks {
+		result, ok := results[strconv.Itoa(i)]
+		if !ok {
+			return nil, fmt.Errorf("missing result for check %d", i)
+		}
+
+		if result.Error != nil {
+			log.Errorw("BatchAllowed", "check", checks[i].String(), "error", result.Error.GetMessage())
+			continue
+		}
+
+		allowed[i] = result.GetAllowed()
+	}
+
+	return allowed, nil
+}
@@ -3,6 +3,7 @@ package openfga
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/openfga/go-sdk/client"
@@ -98,9 +99,13 @@ func Join(sep string, entities ...string) Stringer {
 
 // OidcSubject returns the OIDC subject.
 func OidcSubject(ctx context.Context) Stringer {
-	claim, _ := oidc.GetJWTFromContext(ctx)
+	claim, ok := oidc.GetJWTFromContext(ctx)
 
 	return func() string {
+		if !ok || claim == nil {
+			return ""
+		}
+
 		return claim.Subject
 	}
 }
@@ -129,22 +134,34 @@ type Checker interface {
 	Allowed(ctx context.Context, user User, relation Relation, object Object) (bool, error)
 }
 
-var _ Checker = (*ClientImpl)(nil)
+var (
+	_ Checker     = (*ClientImpl)(nil)
+	_ StoreScoper = (*ClientImpl)(nil)
+)
 
 // ClientImpl is an implementation of the Client interface.
 type ClientImpl struct {
 	client *client.OpenFgaClient
+	opts   *CheckOptions
 }
 
 // Allowed returns true if the user is allowed if the user has the relation on the object.
+// The contextual tuples and the condition context of the context are sent with the check.
 func (c *ClientImpl) Allowed(ctx context.Context, user User, relation Relation, object Object) (bool, error) {
 	body := client.ClientCheckRequest{
-		User:     EntityString(user),
-		Relation: EntityString(relation),
-		Object:   EntityString(object),
+		User:             EntityString(user),
+		Relation:         EntityString(relation),
+		Object:           EntityString(object),
+		ContextualTuples: ClientContextualTuples(GetContextualTuples(ctx)),
+		Context:          ClientConditionContext(GetConditionContext(ctx)),
 	}
 
-	allowed, err := c.client.Check(ctx).Body(body).Execute()
+	opts, err := c.opts.CheckRequestOptions(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	allowed, err := c.client.Check(ctx).Body(body).Options(opts).Execute()
 	if err != nil {
 		return false, err
 	}
@@ -153,6 +170,31 @@ func (c *ClientImpl) Allowed(ctx context.Context, user User, relation Relation,
 }
 
 // NewClient returns a new FGA client.
-func NewClient(c *client.OpenFgaClient) *ClientImpl {
-	return &ClientImpl{c}
+func NewClient(c *client.OpenFgaClient, opts ...ClientOpt) *ClientImpl {
+	return &ClientImpl{client: c, opts: NewCheckOptions(opts...)}
+}
+
+// ModelID returns the id of the pinned authorization model.
+func (c *ClientImpl) ModelID() string {
+	return c.opts.ModelID()
+}
+
+// SetModelID pins the checks to the authorization model.
+func (c *ClientImpl) SetModelID(id string) {
+	c.opts.SetModelID(id)
+}
+
+// ReloadModelID pins the checks to the latest authorization model of the store.
+func (c *ClientImpl) ReloadModelID(ctx context.Context) error {
+	return c.opts.ReloadModelID(ctx, c.client)
+}
+
+// StoreScope returns the store and authorization model of the checks of the request.
+func (c *ClientImpl) StoreScope(ctx context.Context) (string, error) {
+	return c.opts.StoreScope(ctx)
+}
+
+// ReloadModelIDEvery reloads the latest authorization model in the interval until the context is done.
+func (c *ClientImpl) ReloadModelIDEvery(ctx context.Context, interval time.Duration) {
+	c.opts.ReloadModelIDEvery(ctx, c.client, interval)
 }
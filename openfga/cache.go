@@ -0,0 +1,178 @@
+package openfga
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeiss/fiber-authz/internal/cache"
+)
+
+var (
+	_ Checker     = (*Cache)(nil)
+	_ Invalidator = (*Cache)(nil)
+)
+
+// StoreScoper is implemented by checkers whose results depend on the store
+// and the authorization model of the request, e.g. with WithStoreResolver.
+// The caches keep the checks of each scope apart.
+type StoreScoper interface {
+	// StoreScope returns the store and authorization model of the checks of the request.
+	StoreScope(ctx context.Context) (string, error)
+}
+
+// CacheOpts are the options for the check cache.
+type CacheOpts struct {
+	// PositiveTTL is the time an allowed check is cached.
+	PositiveTTL time.Duration
+	// NegativeTTL is the time a denied check is cached.
+	NegativeTTL time.Duration
+	// MaxEntries is the maximum number of cached checks, zero means no limit.
+	MaxEntries int
+	// Clock returns the current time.
+	Clock func() time.Time
+}
+
+// Configure sets the configuration for the cache.
+func (o *CacheOpts) Configure(opts ...CacheOpt) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// CacheOpt is a function that sets an option on the cache.
+type CacheOpt func(*CacheOpts)
+
+// DefaultCacheOpts returns the default cache options.
+func DefaultCacheOpts() CacheOpts {
+	return CacheOpts{
+		PositiveTTL: 10 * time.Second,
+		NegativeTTL: 10 * time.Second,
+		MaxEntries:  10000,
+		Clock:       time.Now,
+	}
+}
+
+// WithCacheTTL sets the time allowed and denied checks are cached.
+func WithCacheTTL(ttl time.Duration) CacheOpt {
+	return func(o *CacheOpts) {
+		o.PositiveTTL = ttl
+		o.NegativeTTL = ttl
+	}
+}
+
+// WithCachePositiveTTL sets the time allowed checks are cached.
+func WithCachePositiveTTL(ttl time.Duration) CacheOpt {
+	return func(o *CacheOpts) {
+		o.PositiveTTL = ttl
+	}
+}
+
+// WithCacheNegativeTTL sets the time denied checks are cached.
+func WithCacheNegativeTTL(ttl time.Duration) CacheOpt {
+	return func(o *CacheOpts) {
+		o.NegativeTTL = ttl
+	}
+}
+
+// WithCacheMaxEntries sets the maximum number of cached checks.
+func WithCacheMaxEntries(n int) CacheOpt {
+	return func(o *CacheOpts) {
+		o.MaxEntries = n
+	}
+}
+
+// WithCacheClock sets the clock of the cache.
+func WithCacheClock(clock func() time.Time) CacheOpt {
+	return func(o *CacheOpts) {
+		o.Clock = clock
+	}
+}
+
+// Cache is a checker that caches the results of another checker.
+type Cache struct {
+	checker Checker
+	cache   *cache.Cache[bool]
+	opts    CacheOpts
+}
+
+// NewCache returns a new caching checker.
+func NewCache(checker Checker, opts ...CacheOpt) *Cache {
+	options := DefaultCacheOpts()
+	options.Configure(opts...)
+
+	return &Cache{
+		checker: checker,
+		cache:   cache.New[bool](options.MaxEntries, options.Clock),
+		opts:    options,
+	}
+}
+
+// Allowed returns the cached result or asks the wrapped checker.
+// Checks are cached per store and authorization model if the checker implements StoreScoper.
+func (c *Cache) Allowed(ctx context.Context, user User, relation Relation, object Object) (bool, error) {
+	// Checks with contextual tuples or condition context depend on the request,
+	// checks with higher consistency must not be answered from the cache.
+	if len(GetContextualTuples(ctx)) > 0 || len(GetConditionContext(ctx)) > 0 || isHigherConsistency(ctx) {
+		return c.checker.Allowed(ctx, user, relation, object)
+	}
+
+	var store string
+
+	if s, ok := c.checker.(StoreScoper); ok {
+		scope, err := s.StoreScope(ctx)
+		if err != nil {
+			return c.checker.Allowed(ctx, user, relation, object)
+		}
+
+		store = scope
+	}
+
+	key := cache.Key{Principal: EntityString(user), Object: EntityString(object), Action: EntityString(relation), Store: store}
+
+	return c.cache.Do(ctx, key, func() (bool, time.Duration, error) {
+		allowed, err := c.checker.Allowed(ctx, user, relation, object)
+		if err != nil {
+			return false, 0, err
+		}
+
+		if allowed {
+			return allowed, c.opts.PositiveTTL, nil
+		}
+
+		return allowed, c.opts.NegativeTTL, nil
+	})
+}
+
+// InvalidateUser removes all cached checks of the user.
+func (c *Cache) InvalidateUser(user User) {
+	c.cache.DeleteFunc(func(k cache.Key) bool {
+		return k.Principal == EntityString(user)
+	})
+}
+
+// InvalidateObject removes all cached checks of the object.
+func (c *Cache) InvalidateObject(object Object) {
+	c.cache.DeleteFunc(func(k cache.Key) bool {
+		return k.Object == EntityString(object)
+	})
+}
+
+// Invalidate removes all cached checks of the users and objects of the tuples.
+// Checks that depend on the tuples through other users or objects are kept until they expire.
+func (c *Cache) Invalidate(tuples ...Tuple) {
+	for _, t := range tuples {
+		c.InvalidateUser(t.User)
+		c.InvalidateObject(t.Object)
+	}
+}
+
+// Flush removes all cached checks.
+func (c *Cache) Flush() {
+	c.cache.Flush()
+}
+
+func isHigherConsistency(ctx context.Context) bool {
+	consistency, ok := GetConsistency(ctx)
+
+	return ok && consistency == ConsistencyHigher
+}
@@ -0,0 +1,135 @@
+package openfga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Combinator combines the checks of a group.
+type Combinator string
+
+const (
+	// CombinatorAll requires all checks of the group to be allowed.
+	CombinatorAll Combinator = "all"
+	// CombinatorAny requires one check of the group to be allowed.
+	CombinatorAny Combinator = "any"
+)
+
+// ErrMixedCombinators is returned when a group has all and any checks.
+var ErrMixedCombinators = errors.New("all and any can not be combined in the same group")
+
+// Checks is a single check or a group of checks.
+type Checks struct {
+	// Check is the single check.
+	Check *Check
+	// Combinator combines the checks of the group.
+	Combinator Combinator
+	// Checks are the checks of the group.
+	Checks []Checks
+}
+
+// Flatten returns the single checks in the order of the tree.
+func (c Checks) Flatten() []Check {
+	if c.Check != nil {
+		return []Check{*c.Check}
+	}
+
+	checks := []Check{}
+	for _, child := range c.Checks {
+		checks = append(checks, child.Flatten()...)
+	}
+
+	return checks
+}
+
+// String returns the string representation of the check.
+func (c Check) String() string {
+	return fmt.Sprintf("%s %s %s", c.User, c.Relation, c.Object)
+}
+
+// Result is the result of the evaluation of checks.
+type Result struct {
+	// Allowed is true if the checks are allowed.
+	Allowed bool
+	// Failed are the checks that caused the denial.
+	Failed []Check
+}
+
+// Reason returns the checks that caused the denial.
+func (r Result) Reason() string {
+	failed := make([]string, len(r.Failed))
+	for i, c := range r.Failed {
+		failed[i] = c.String()
+	}
+
+	return strings.Join(failed, ", ")
+}
+
+type resultKey struct{}
+
+// WithResult returns a new context with the result of the evaluation of the checks.
+func WithResult(ctx context.Context, res Result) context.Context {
+	return context.WithValue(ctx, resultKey{}, res)
+}
+
+// GetResult returns the result of the evaluation of the checks from the context.
+func GetResult(ctx context.Context) (Result, bool) {
+	res, ok := ctx.Value(resultKey{}).(Result)
+
+	return res, ok
+}
+
+// Evaluate evaluates the checks with a single batch check.
+func Evaluate(ctx context.Context, checker Checker, checks Checks) (Result, error) {
+	flat := checks.Flatten()
+
+	results, err := BatchAllowed(ctx, checker, flat)
+	if err != nil {
+		return Result{}, err
+	}
+
+	i := 0
+
+	return evaluate(checks, flat, results, &i), nil
+}
+
+func evaluate(checks Checks, flat []Check, results []bool, i *int) Result {
+	if checks.Check != nil {
+		r := Result{Allowed: results[*i]}
+		if !r.Allowed {
+			r.Failed = []Check{flat[*i]}
+		}
+
+		*i++
+
+		return r
+	}
+
+	res := Result{Allowed: checks.Combinator == CombinatorAll}
+
+	for _, child := range checks.Checks {
+		r := evaluate(child, flat, results, i)
+
+		switch checks.Combinator {
+		case CombinatorAll:
+			if !r.Allowed {
+				res.Allowed = false
+				res.Failed = append(res.Failed, r.Failed...)
+			}
+		case CombinatorAny:
+			if r.Allowed {
+				res.Allowed = true
+			} else {
+				res.Failed = append(res.Failed, r.Failed...)
+			}
+		}
+	}
+
+	if res.Allowed {
+		res.Failed = nil
+	}
+
+	return res
+}
@@ -0,0 +1,144 @@
+package openfga_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/mitchellh/mapstructure"
+	"github.com/stretchr/testify/require"
+	"github.com/zeiss/fiber-authz/oas"
+	"github.com/zeiss/fiber-authz/oas/oidc"
+	"github.com/zeiss/fiber-authz/openfga"
+	"github.com/zeiss/fiber-authz/openfga/memory"
+)
+
+const checksModel = `model
+  schema 1.1
+type user
+type team
+  relations
+    define editor: [user]
+    define viewer: [user] or editor
+type workload
+  relations
+    define viewer: [user]
+`
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	store, err := memory.Parse([]byte(checksModel))
+	require.NoError(t, err)
+
+	err = store.Write(
+		memory.Tuple{User: "user:alice", Relation: "editor", Object: "team:zeiss"},
+		memory.Tuple{User: "user:alice", Relation: "viewer", Object: "workload:foo"},
+		memory.Tuple{User: "user:bob", Relation: "viewer", Object: "team:zeiss"},
+	)
+	require.NoError(t, err)
+
+	checks := func(user openfga.User) openfga.Checks {
+		return openfga.Checks{
+			Combinator: openfga.CombinatorAll,
+			Checks: []openfga.Checks{
+				{Check: &openfga.Check{User: user, Relation: "editor", Object: "team:zeiss"}},
+				{
+					Combinator: openfga.CombinatorAny,
+					Checks: []openfga.Checks{
+						{Check: &openfga.Check{User: user, Relation: "viewer", Object: "workload:foo"}},
+						{Check: &openfga.Check{User: user, Relation: "viewer", Object: "workload:bar"}},
+					},
+				},
+			},
+		}
+	}
+
+	res, err := openfga.Evaluate(context.Background(), store, checks("user:alice"))
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Empty(t, res.Failed)
+
+	res, err = openfga.Evaluate(context.Background(), store, checks("user:bob"))
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Len(t, res.Failed, 3)
+	require.Equal(t, "user:bob editor team:zeiss, user:bob viewer workload:foo, user:bob viewer workload:bar", res.Reason())
+}
+
+func TestBuildChecksMixedCombinators(t *testing.T) {
+	t.Parallel()
+
+	ext := map[string]interface{}{
+		"all": []interface{}{map[string]interface{}{"relation": map[string]interface{}{"name": "editor"}}},
+		"any": []interface{}{map[string]interface{}{"relation": map[string]interface{}{"name": "viewer"}}},
+	}
+
+	opts := &openfga.OasFGAAuthzOptions{}
+	require.NoError(t, mapstructure.Decode(ext, opts))
+	require.Equal(t, "editor", opts.All[0].Relation.Name)
+
+	input := &openapi3filter.AuthenticationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request: httptest.NewRequest(http.MethodGet, "/", nil),
+		},
+	}
+
+	_, err := openfga.BuildChecks(context.Background(), input, opts, openfga.DefaultUserProviders())
+	require.ErrorIs(t, err, openfga.ErrMixedCombinators)
+}
+
+func TestBuildChecksContextualTuples(t *testing.T) {
+	t.Parallel()
+
+	store, err := memory.Parse([]byte(checksModel))
+	require.NoError(t, err)
+
+	ext := map[string]interface{}{
+		"user":     map[string]interface{}{"namespace": "user"},
+		"relation": map[string]interface{}{"name": "viewer"},
+		"object": map[string]interface{}{
+			"namespace":  "team",
+			"components": []interface{}{map[string]interface{}{"in": "value", "value": "admins"}},
+		},
+		"contextual_tuples": []interface{}{
+			map[string]interface{}{"relation": "editor", "namespace": "team", "claim": "groups"},
+		},
+		"context": map[string]interface{}{
+			"client_ip": map[string]interface{}{"in": "ip"},
+		},
+	}
+
+	opts := &openfga.OasFGAAuthzOptions{}
+	require.NoError(t, mapstructure.Decode(ext, opts))
+
+	input := &openapi3filter.AuthenticationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request: httptest.NewRequest(http.MethodGet, "/", nil),
+		},
+	}
+
+	claims := &oas.AuthClaims{
+		Subject: "alice",
+		Claims:  map[string]interface{}{"groups": []interface{}{"admins", "devs"}},
+	}
+	ctx := oidc.WithJWT(context.Background(), claims)
+
+	checks, err := openfga.BuildChecks(ctx, input, opts, openfga.DefaultUserProviders())
+	require.NoError(t, err)
+	require.Equal(t, []openfga.Tuple{
+		{User: "user:alice", Relation: "editor", Object: "team:admins"},
+		{User: "user:alice", Relation: "editor", Object: "team:devs"},
+	}, checks.Check.ContextualTuples)
+	require.Equal(t, map[string]interface{}{"client_ip": "192.0.2.1"}, checks.Check.Context)
+
+	res, err := openfga.Evaluate(context.Background(), store, checks)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+
+	allowed, err := store.Allowed(context.Background(), "user:alice", "viewer", "team:admins")
+	require.NoError(t, err)
+	require.False(t, allowed)
+}
@@ -0,0 +1,105 @@
+package openfga
+
+import (
+	"context"
+
+	"github.com/openfga/go-sdk/client"
+)
+
+// Tuple is a relationship tuple.
+type Tuple struct {
+	// User is the user of the tuple.
+	User User `json:"user"`
+	// Relation is the relation of the tuple.
+	Relation Relation `json:"relation"`
+	// Object is the object of the tuple.
+	Object Object `json:"object"`
+}
+
+type contextKey int
+
+const (
+	contextualTuples contextKey = iota
+	conditionContext
+)
+
+// WithContextualTuples returns a new context with the contextual tuples.
+// The tuples are appended to the contextual tuples already in the context
+// and are sent with every check.
+func WithContextualTuples(ctx context.Context, tuples ...Tuple) context.Context {
+	merged := append([]Tuple{}, GetContextualTuples(ctx)...)
+	merged = append(merged, tuples...)
+
+	return context.WithValue(ctx, contextualTuples, merged)
+}
+
+// GetContextualTuples returns the contextual tuples from the context.
+func GetContextualTuples(ctx context.Context) []Tuple {
+	tuples, _ := ctx.Value(contextualTuples).([]Tuple)
+
+	return tuples
+}
+
+// WithConditionContext returns a new context with the condition context.
+// The values are merged with the condition context already in the context
+// and are sent with every check.
+func WithConditionContext(ctx context.Context, values map[string]interface{}) context.Context {
+	merged := map[string]interface{}{}
+
+	for k, v := range GetConditionContext(ctx) {
+		merged[k] = v
+	}
+
+	for k, v := range values {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, conditionContext, merged)
+}
+
+// GetConditionContext returns the condition context from the context.
+func GetConditionContext(ctx context.Context) map[string]interface{} {
+	values, _ := ctx.Value(conditionContext).(map[string]interface{})
+
+	return values
+}
+
+// ClientContextualTuples converts the tuples to the OpenFGA client representation.
+func ClientContextualTuples(tuples []Tuple) []client.ClientContextualTupleKey {
+	if len(tuples) == 0 {
+		return nil
+	}
+
+	keys := make([]client.ClientContextualTupleKey, len(tuples))
+	for i, t := range tuples {
+		keys[i] = client.ClientContextualTupleKey{
+			User:     EntityString(t.User),
+			Relation: EntityString(t.Relation),
+			Object:   EntityString(t.Object),
+		}
+	}
+
+	return keys
+}
+
+// ClientConditionContext converts the condition context to the OpenFGA client representation.
+func ClientConditionContext(values map[string]interface{}) *map[string]interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+
+	return &values
+}
+
+// withCheckContext returns a new context with the contextual tuples and condition context of the check.
+func withCheckContext(ctx context.Context, check Check) context.Context {
+	if len(check.ContextualTuples) > 0 {
+		ctx = WithContextualTuples(ctx, check.ContextualTuples...)
+	}
+
+	if len(check.Context) > 0 {
+		ctx = WithConditionContext(ctx, check.Context)
+	}
+
+	return ctx
+}
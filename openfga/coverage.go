@@ -0,0 +1,160 @@
+package openfga
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+// PublicExtensionName is the extension that marks an operation as public.
+const PublicExtensionName = "x-fiber-authz-public"
+
+// CoverageStatus is the authorization status of an operation.
+type CoverageStatus string
+
+// Authorization status of the operations.
+const (
+	// CoverageProtected is an operation with the extension.
+	CoverageProtected CoverageStatus = "protected"
+	// CoveragePublic is an operation that is marked as public.
+	CoveragePublic CoverageStatus = "public"
+	// CoverageUnannotated is an operation without the extension that is not marked as public.
+	CoverageUnannotated CoverageStatus = "unannotated"
+)
+
+// OperationCoverage is the authorization status of an operation.
+type OperationCoverage struct {
+	// Method is the method of the operation.
+	Method string
+	// Path is the path of the operation.
+	Path string
+	// Status is the authorization status of the operation.
+	Status CoverageStatus
+	// Secured is true if the operation has a security requirement,
+	// the authentication function is only called for these operations.
+	Secured bool
+}
+
+// String returns the string representation of the coverage.
+func (o OperationCoverage) String() string {
+	if !o.Secured && o.Status != CoveragePublic {
+		return fmt.Sprintf("%s %s: %s (no security requirement)", o.Method, o.Path, o.Status)
+	}
+
+	return fmt.Sprintf("%s %s: %s", o.Method, o.Path, o.Status)
+}
+
+// HasSecurity returns true if the operation or the document has a security requirement
+// and none of the requirements allows anonymous access.
+func HasSecurity(doc *openapi3.T, op *openapi3.Operation) bool {
+	security := op.Security
+	if security == nil {
+		security = &doc.Security
+	}
+
+	if len(*security) == 0 {
+		return false
+	}
+
+	for _, req := range *security {
+		if len(req) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsPublic returns true if the operation is marked as public.
+func IsPublic(op *openapi3.Operation) bool {
+	public, ok := op.Extensions[PublicExtensionName].(bool)
+
+	return ok && public
+}
+
+// OperationStatus returns the authorization status of the operation.
+func OperationStatus(op *openapi3.Operation, name ...string) CoverageStatus {
+	ext := DefaultExtensionName
+	if len(name) > 0 {
+		ext = name[0]
+	}
+
+	if _, ok := op.Extensions[ext]; ok {
+		return CoverageProtected
+	}
+
+	if IsPublic(op) {
+		return CoveragePublic
+	}
+
+	return CoverageUnannotated
+}
+
+// Coverage returns the authorization status of all operations of the document,
+// sorted by path and method.
+func Coverage(doc *openapi3.T, name ...string) []OperationCoverage {
+	report := []OperationCoverage{}
+
+	if doc.Paths == nil {
+		return report
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(doc.Paths.Map())) {
+		ops := doc.Paths.Value(path).Operations()
+
+		for _, method := range slices.Sorted(maps.Keys(ops)) {
+			report = append(report, OperationCoverage{
+				Method:  method,
+				Path:    path,
+				Status:  OperationStatus(ops[method], name...),
+				Secured: HasSecurity(doc, ops[method]),
+			})
+		}
+	}
+
+	return report
+}
+
+// FailClosed returns a middleware that denies the operations of the document without the extension,
+// unless they are marked as public. The authentication function of the request validator is only
+// called for operations with a security requirement, so operations with the extension but without
+// a security requirement are denied as well. Requests without an operation are passed on.
+func FailClosed(doc *openapi3.T, opts ...OasAuthenticateOpt) fiber.Handler {
+	options := OasDefaultAuthenticateOpts()
+	options.Configure(opts...)
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		panic(err)
+	}
+
+	return func(c *fiber.Ctx) error {
+		r, err := adaptor.ConvertRequest(c, false)
+		if err != nil {
+			return err
+		}
+
+		route, _, err := router.FindRoute(r)
+		if err != nil {
+			return c.Next()
+		}
+
+		switch OperationStatus(route.Operation, options.Extension) {
+		case CoveragePublic:
+			return c.Next()
+		case CoverageUnannotated:
+			return ErrUnannotatedOperation
+		}
+
+		if !HasSecurity(doc, route.Operation) {
+			return ErrUnannotatedOperation
+		}
+
+		return c.Next()
+	}
+}
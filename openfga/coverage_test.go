@@ -0,0 +1,132 @@
+package openfga_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/zeiss/fiber-authz/openfga"
+)
+
+const coverageSpec = `
+openapi: 3.0.0
+info:
+  title: test
+  version: 1.0.0
+components:
+  securitySchemes:
+    bearer:
+      type: http
+      scheme: bearer
+paths:
+  /accounts:
+    get:
+      security:
+        - bearer: []
+      x-fiber-authz-fga:
+        relation:
+          name: reader
+        object:
+          namespace: system
+      responses:
+        "200":
+          description: ok
+  /health:
+    get:
+      x-fiber-authz-public: true
+      responses:
+        "200":
+          description: ok
+  /teams:
+    get:
+      responses:
+        "200":
+          description: ok
+    post:
+      x-fiber-authz-fga:
+        relation:
+          name: admin
+        object:
+          namespace: system
+      responses:
+        "200":
+          description: ok
+`
+
+func TestCoverage(t *testing.T) {
+	t.Parallel()
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(coverageSpec))
+	require.NoError(t, err)
+
+	require.Equal(t, []openfga.OperationCoverage{
+		{Method: "GET", Path: "/accounts", Status: openfga.CoverageProtected, Secured: true},
+		{Method: "GET", Path: "/health", Status: openfga.CoveragePublic},
+		{Method: "GET", Path: "/teams", Status: openfga.CoverageUnannotated},
+		{Method: "POST", Path: "/teams", Status: openfga.CoverageProtected},
+	}, openfga.Coverage(doc))
+}
+
+func TestOasAuthenticateFailClosed(t *testing.T) {
+	t.Parallel()
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(coverageSpec))
+	require.NoError(t, err)
+
+	input := func(path, method string) *openapi3filter.AuthenticationInput {
+		return &openapi3filter.AuthenticationInput{
+			RequestValidationInput: &openapi3filter.RequestValidationInput{
+				Route: &routers.Route{Operation: doc.Paths.Value(path).GetOperation(method)},
+			},
+		}
+	}
+
+	auth := openfga.OasAuthenticate(openfga.WithFailClosed())
+	require.NoError(t, auth(context.Background(), input("/health", "GET")))
+	require.ErrorIs(t, auth(context.Background(), input("/teams", "GET")), openfga.ErrUnannotatedOperation)
+
+	auth = openfga.OasAuthenticate()
+	require.NoError(t, auth(context.Background(), input("/teams", "GET")))
+}
+
+func TestFailClosed(t *testing.T) {
+	t.Parallel()
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(coverageSpec))
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(openfga.FailClosed(doc))
+	app.Use(func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{name: "protected", method: http.MethodGet, path: "/accounts", status: fiber.StatusOK},
+		{name: "public", method: http.MethodGet, path: "/health", status: fiber.StatusOK},
+		{name: "unannotated", method: http.MethodGet, path: "/teams", status: fiber.StatusForbidden},
+		{name: "annotated without security", method: http.MethodPost, path: "/teams", status: fiber.StatusForbidden},
+		{name: "unknown operation", method: http.MethodGet, path: "/unknown", status: fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			require.NoError(t, err)
+			require.Equal(t, tt.status, res.StatusCode)
+		})
+	}
+}
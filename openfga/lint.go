@@ -0,0 +1,184 @@
+package openfga
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Locations of the components.
+const (
+	// InPath is a path parameter.
+	InPath = "path"
+	// InQuery is a query parameter.
+	InQuery = "query"
+	// InHeader is a request header.
+	InHeader = "header"
+	// InCookie is a cookie.
+	InCookie = "cookie"
+	// InBody is a JSON pointer into the request body.
+	InBody = "body"
+	// InClaim is a claim of the OIDC token.
+	InClaim = "claim"
+	// InValue is a static value.
+	InValue = "value"
+	// InIP is the IP address of the client.
+	InIP = "ip"
+	// InTime is the current time in RFC 3339 format.
+	InTime = "time"
+)
+
+// SupportedComponentLocations are the locations that are supported by ResolveObject.
+var SupportedComponentLocations = []string{InPath, InQuery, InHeader, InCookie, InBody, InClaim, InValue, InIP, InTime}
+
+// Diagnostic is a problem with the extension of an operation.
+type Diagnostic struct {
+	// Method is the method of the operation.
+	Method string
+	// Path is the path of the operation.
+	Path string
+	// Message describes the problem.
+	Message string
+}
+
+// String returns the string representation of the diagnostic.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s %s: %s", d.Method, d.Path, d.Message)
+}
+
+// Lint checks the extensions of all operations of the document.
+// The extensions are decoded strictly, so unknown keys are reported.
+// Each component must refer to a declared parameter of the operation.
+func Lint(doc *openapi3.T, name ...string) []Diagnostic {
+	ext := DefaultExtensionName
+	if len(name) > 0 {
+		ext = name[0]
+	}
+
+	diags := []Diagnostic{}
+
+	if doc.Paths == nil {
+		return diags
+	}
+
+	for path, pathItem := range doc.Paths.Map() {
+		for method, op := range pathItem.Operations() {
+			v, ok := op.Extensions[ext]
+			if !ok {
+				continue
+			}
+
+			for _, msg := range lintOperation(pathItem, op, v) {
+				diags = append(diags, Diagnostic{Method: method, Path: path, Message: msg})
+			}
+		}
+	}
+
+	slices.SortFunc(diags, func(a, b Diagnostic) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	return diags
+}
+
+func lintOperation(pathItem *openapi3.PathItem, op *openapi3.Operation, ext interface{}) []string {
+	opts := &OasFGAAuthzOptions{}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		ErrorUnused: true,
+		Result:      opts,
+	})
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	if err := decoder.Decode(ext); err != nil {
+		return []string{err.Error()}
+	}
+
+	params := append(openapi3.Parameters{}, pathItem.Parameters...)
+	params = append(params, op.Parameters...)
+
+	return lintOptions(params, op, opts, "")
+}
+
+func lintOptions(params openapi3.Parameters, op *openapi3.Operation, opts *OasFGAAuthzOptions, prefix string) []string {
+	msgs := []string{}
+
+	if opts.IsGroup() {
+		if len(opts.All) > 0 && len(opts.Any) > 0 {
+			msgs = append(msgs, prefix+ErrMixedCombinators.Error())
+		}
+
+		for i := range opts.All {
+			msgs = append(msgs, lintOptions(params, op, &opts.All[i], fmt.Sprintf("%sall %d: ", prefix, i))...)
+		}
+
+		for i := range opts.Any {
+			msgs = append(msgs, lintOptions(params, op, &opts.Any[i], fmt.Sprintf("%sany %d: ", prefix, i))...)
+		}
+
+		return msgs
+	}
+
+	if opts.Relation.Name == "" {
+		msgs = append(msgs, prefix+"relation name is empty")
+	}
+
+	if opts.Object.Namespace == "" {
+		msgs = append(msgs, prefix+"object namespace is empty")
+	}
+
+	for i, c := range opts.Object.Components {
+		if msg := lintComponent(params, op, c); msg != "" {
+			msgs = append(msgs, fmt.Sprintf("%sobject component %d: %s", prefix, i, msg))
+		}
+	}
+
+	for i, t := range opts.ContextualTuples {
+		if t.Relation == "" || t.Namespace == "" || t.Claim == "" {
+			msgs = append(msgs, fmt.Sprintf("%scontextual tuple %d: relation, namespace and claim are required", prefix, i))
+		}
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(opts.Context)) {
+		if msg := lintComponent(params, op, opts.Context[k]); msg != "" {
+			msgs = append(msgs, fmt.Sprintf("%scontext %q: %s", prefix, k, msg))
+		}
+	}
+
+	return msgs
+}
+
+func lintComponent(params openapi3.Parameters, op *openapi3.Operation, c OasFGAAuthzOptionComponent) string {
+	switch {
+	case !slices.Contains(SupportedComponentLocations, c.In):
+		return fmt.Sprintf("unsupported in %q", c.In)
+	case c.In == InValue:
+		if c.Value == "" {
+			return "value is empty"
+		}
+	case c.In == InBody:
+		if op.RequestBody == nil {
+			return "request body is not declared"
+		}
+
+		if c.Name != "" && !strings.HasPrefix(c.Name, "/") {
+			return fmt.Sprintf("invalid JSON pointer %q", c.Name)
+		}
+	case c.In == InIP || c.In == InTime:
+		// The client address and the time have no name.
+	case c.Name == "":
+		return "name is empty"
+	case c.In == InClaim:
+		// Claims are not declared in the document.
+	case params.GetByInAndName(c.In, c.Name) == nil:
+		return fmt.Sprintf("%s parameter %q is not declared", c.In, c.Name)
+	}
+
+	return ""
+}
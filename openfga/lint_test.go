@@ -0,0 +1,103 @@
+package openfga_test
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/require"
+	"github.com/zeiss/fiber-authz/openfga"
+)
+
+const lintSpec = `
+openapi: 3.0.0
+info:
+  title: test
+  version: 1.0.0
+paths:
+  /teams/{teamId}:
+    parameters:
+      - in: path
+        name: teamId
+        required: true
+        schema:
+          type: string
+    get:
+      x-fiber-authz-fga:
+        user:
+          namespace: user
+        relation:
+          name: can_read
+        object:
+          namespace: team
+          components:
+            - in: path
+              name: teamId
+      responses:
+        "200":
+          description: ok
+    put:
+      x-fiber-authz-fga:
+        user:
+          namespace: user
+        relation:
+          name: can_write
+        object:
+          namespace: team
+          components:
+            - in: params
+              name: teamId
+      responses:
+        "200":
+          description: ok
+    delete:
+      x-fiber-authz-fga:
+        user:
+          namespace: user
+        relation:
+          nmae: can_delete
+        object:
+          namespace: team
+      responses:
+        "200":
+          description: ok
+  /workloads:
+    get:
+      parameters:
+        - in: query
+          name: team
+          schema:
+            type: string
+      x-fiber-authz-fga:
+        user:
+          namespace: user
+        relation:
+          name: can_read
+        object:
+          namespace: team
+          components:
+            - in: query
+              name: teamId
+      responses:
+        "200":
+          description: ok
+`
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(lintSpec))
+	require.NoError(t, err)
+
+	diags := openfga.Lint(doc)
+
+	msgs := []string{}
+	for _, d := range diags {
+		msgs = append(msgs, d.String())
+	}
+
+	require.Len(t, msgs, 3)
+	require.Contains(t, msgs[0], "DELETE /teams/{teamId}: ")
+	require.Contains(t, msgs[0], "nmae")
+	require.Equal(t, `GET /workloads: object component 0: query parameter "teamId" is not declared`, msgs[1])
+	require.Equal(t, `PUT /teams/{teamId}: object component 0: unsupported in "params"`, msgs[2])
+}
@@ -0,0 +1,164 @@
+package openfga
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/openfga/go-sdk/client"
+)
+
+// Lister is an interface for listing the objects of a user.
+type Lister interface {
+	// ListObjects returns the objects of the type on which the user has the relation.
+	ListObjects(ctx context.Context, user User, relation Relation, typ string) ([]Object, error)
+}
+
+var _ Lister = (*ClientImpl)(nil)
+
+// ListObjects returns the objects of the type on which the user has the relation.
+// The contextual tuples and the condition context of the context are sent with the request.
+func (c *ClientImpl) ListObjects(ctx context.Context, user User, relation Relation, typ string) ([]Object, error) {
+	body := client.ClientListObjectsRequest{
+		User:             EntityString(user),
+		Relation:         EntityString(relation),
+		Type:             typ,
+		ContextualTuples: ClientContextualTuples(GetContextualTuples(ctx)),
+		Context:          ClientConditionContext(GetConditionContext(ctx)),
+	}
+
+	opts, err := c.opts.ListObjectsRequestOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.client.ListObjects(ctx).Body(body).Options(opts).Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]Object, 0, len(res.GetObjects()))
+	for _, o := range res.GetObjects() {
+		objects = append(objects, Object(o))
+	}
+
+	return objects, nil
+}
+
+// ObjectID returns the id of the object without the type.
+func ObjectID(object Object) string {
+	_, id, ok := strings.Cut(EntityString(object), DefaultNamespaceSeparator)
+	if !ok {
+		return EntityString(object)
+	}
+
+	return id
+}
+
+type allowedObjectsKey struct{}
+
+// WithAllowedObjects returns a new context with the ids of the allowed objects.
+func WithAllowedObjects(ctx context.Context, ids []string) context.Context {
+	return context.WithValue(ctx, allowedObjectsKey{}, ids)
+}
+
+// GetAllowedObjects returns the ids of the allowed objects from the context.
+func GetAllowedObjects(ctx context.Context) ([]string, bool) {
+	ids, ok := ctx.Value(allowedObjectsKey{}).([]string)
+
+	return ids, ok
+}
+
+// Filter returns the items with an allowed id.
+// No item is returned if the context has no allowed objects.
+func Filter[T any](ctx context.Context, items []T, id func(T) string) []T {
+	ids, _ := GetAllowedObjects(ctx)
+
+	allowed := make(map[string]struct{}, len(ids))
+	for _, v := range ids {
+		allowed[v] = struct{}{}
+	}
+
+	filtered := make([]T, 0, len(items))
+	for _, item := range items {
+		if _, ok := allowed[id(item)]; ok {
+			filtered = append(filtered, item)
+		}
+	}
+
+	return filtered
+}
+
+// ListUserResolver resolves the user of the request.
+type ListUserResolver func(c *fiber.Ctx) (User, error)
+
+// DefaultListUserResolver returns the subject of the OIDC token in the user namespace.
+// It returns ErrNoIdentity if the request has no subject.
+func DefaultListUserResolver(c *fiber.Ctx) (User, error) {
+	subject := OidcSubject(c.UserContext())
+	if subject() == "" {
+		return NoopUser, ErrNoIdentity
+	}
+
+	return NewUser(Namespace("user"), subject), nil
+}
+
+// ListOpts are the options for the list middleware.
+type ListOpts struct {
+	// UserResolver resolves the user of the request.
+	UserResolver ListUserResolver
+}
+
+// Configure sets the configuration for the list middleware.
+func (o *ListOpts) Configure(opts ...ListOpt) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// ListOpt ...
+type ListOpt func(*ListOpts)
+
+// DefaultListOpts returns the default options.
+func DefaultListOpts() ListOpts {
+	return ListOpts{
+		UserResolver: DefaultListUserResolver,
+	}
+}
+
+// WithListUserResolver sets the user resolver of the list middleware.
+func WithListUserResolver(resolver ListUserResolver) ListOpt {
+	return func(o *ListOpts) {
+		o.UserResolver = resolver
+	}
+}
+
+// ListObjects is a middleware that lists the objects of the type on which the user has the relation.
+// The ids of the objects are stored in the user context, see GetAllowedObjects and Filter.
+func ListObjects(lister Lister, relation Relation, typ string, opts ...ListOpt) fiber.Handler {
+	options := DefaultListOpts()
+	options.Configure(opts...)
+
+	return func(c *fiber.Ctx) error {
+		user, err := options.UserResolver(c)
+		if err != nil {
+			return err
+		}
+
+		// nolint:contextcheck
+		objects, err := lister.ListObjects(c.UserContext(), user, relation, typ)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]string, len(objects))
+		for i, o := range objects {
+			ids[i] = ObjectID(o)
+		}
+
+		// nolint:contextcheck
+		c.SetUserContext(WithAllowedObjects(c.UserContext(), ids))
+
+		return c.Next()
+	}
+}
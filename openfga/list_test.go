@@ -0,0 +1,88 @@
+package openfga_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/zeiss/fiber-authz/openfga"
+	"github.com/zeiss/fiber-authz/openfga/memory"
+)
+
+func TestListObjects(t *testing.T) {
+	t.Parallel()
+
+	store, err := memory.Parse([]byte(checksModel))
+	require.NoError(t, err)
+
+	err = store.Write(
+		memory.Tuple{User: "user:alice", Relation: "viewer", Object: "workload:foo"},
+		memory.Tuple{User: "user:alice", Relation: "viewer", Object: "workload:baz"},
+		memory.Tuple{User: "user:bob", Relation: "viewer", Object: "workload:bar"},
+	)
+	require.NoError(t, err)
+
+	type workload struct {
+		ID string `json:"id"`
+	}
+
+	workloads := []workload{{ID: "foo"}, {ID: "bar"}, {ID: "baz"}}
+
+	app := fiber.New()
+	app.Get("/workloads",
+		openfga.ListObjects(store, "viewer", "workload", openfga.WithListUserResolver(func(c *fiber.Ctx) (openfga.User, error) {
+			return openfga.User("user:" + c.Get("X-User")), nil
+		})),
+		func(c *fiber.Ctx) error {
+			return c.JSON(openfga.Filter(c.UserContext(), workloads, func(w workload) string { return w.ID }))
+		},
+	)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/workloads", nil)
+	req.Header.Set("X-User", "alice")
+
+	res, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	var body []workload
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	require.Equal(t, []workload{{ID: "foo"}, {ID: "baz"}}, body)
+
+	res, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/workloads", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	body = nil
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	require.Empty(t, body)
+}
+
+type listerFunc func(ctx context.Context, user openfga.User, relation openfga.Relation, typ string) ([]openfga.Object, error)
+
+func (f listerFunc) ListObjects(ctx context.Context, user openfga.User, relation openfga.Relation, typ string) ([]openfga.Object, error) {
+	return f(ctx, user, relation, typ)
+}
+
+func TestListObjectsNoIdentity(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	lister := listerFunc(func(context.Context, openfga.User, openfga.Relation, string) ([]openfga.Object, error) {
+		calls++
+		return nil, nil
+	})
+
+	app := fiber.New()
+	app.Get("/workloads", openfga.ListObjects(lister, "viewer", "workload"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/workloads", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusUnauthorized, res.StatusCode)
+	require.Zero(t, calls)
+}
@@ -0,0 +1,417 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	fgasdk "github.com/openfga/go-sdk"
+	"github.com/zeiss/fiber-authz/openfga"
+	"github.com/zeiss/fiber-authz/openfga/model"
+)
+
+// DefaultMaxDepth is the default maximum depth of the resolution of a check,
+// the same as the default of the OpenFGA server.
+const DefaultMaxDepth = 25
+
+// Wildcard is the id that relates all users of a type.
+const Wildcard = "*"
+
+// UsersetSeparator is the separator of an object and a relation in a userset.
+const UsersetSeparator = "#"
+
+var (
+	// ErrResolutionDepthExceeded is returned when a check exceeds the maximum depth.
+	ErrResolutionDepthExceeded = errors.New("memory: resolution depth exceeded")
+	// ErrUnknownType is returned when a type is not defined by the model.
+	ErrUnknownType = errors.New("memory: unknown type")
+	// ErrUnknownRelation is returned when a relation is not defined on a type.
+	ErrUnknownRelation = errors.New("memory: unknown relation")
+	// ErrInvalidTuple is returned when a tuple can not be written.
+	ErrInvalidTuple = errors.New("memory: invalid tuple")
+)
+
+var (
+	_ openfga.Checker = (*Store)(nil)
+	_ openfga.Lister  = (*Store)(nil)
+)
+
+// Tuple is a relationship tuple.
+// The user is a type:id, type:* or type:id#relation.
+type Tuple = openfga.Tuple
+
+// Opts are the options for the store.
+type Opts struct {
+	// MaxDepth is the maximum depth of the resolution of a check.
+	MaxDepth int
+}
+
+// Configure sets the configuration for the store.
+func (o *Opts) Configure(opts ...Opt) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// Opt is a function that sets an option on the store.
+type Opt func(*Opts)
+
+// DefaultOpts returns the default options.
+func DefaultOpts() Opts {
+	return Opts{
+		MaxDepth: DefaultMaxDepth,
+	}
+}
+
+// WithMaxDepth sets the maximum depth of the resolution of a check.
+func WithMaxDepth(depth int) Opt {
+	return func(o *Opts) {
+		o.MaxDepth = depth
+	}
+}
+
+// Store is an in-memory relationship engine that implements a subset of OpenFGA.
+//
+// It supports direct relations, wildcards, usersets, computed usersets,
+// tuple to usersets, unions, intersections and exclusions.
+// Conditions are not supported.
+type Store struct {
+	opts  Opts
+	types map[string]fgasdk.TypeDefinition
+
+	mu     sync.RWMutex
+	tuples map[string]map[openfga.User]struct{}
+}
+
+// New returns a new store for the model.
+// It returns an error if the model references unknown types or relations.
+func New(m *model.Model, opts ...Opt) (*Store, error) {
+	options := DefaultOpts()
+	options.Configure(opts...)
+
+	if err := model.Validate(m); err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		opts:   options,
+		types:  model.Types(m),
+		tuples: make(map[string]map[openfga.User]struct{}),
+	}
+
+	return s, nil
+}
+
+// Parse returns a new store for a model in the OpenFGA DSL or JSON format.
+func Parse(b []byte, opts ...Opt) (*Store, error) {
+	m, err := model.Parse(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(m, opts...)
+}
+
+// Write writes the tuples. Existing tuples are ignored.
+// No tuple is written if one of them is invalid.
+func (s *Store) Write(tuples ...Tuple) error {
+	for _, t := range tuples {
+		if err := s.validateTuple(t); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range tuples {
+		key := tupleKey(openfga.EntityString(t.Object), openfga.EntityString(t.Relation))
+
+		users, ok := s.tuples[key]
+		if !ok {
+			users = make(map[openfga.User]struct{})
+			s.tuples[key] = users
+		}
+
+		users[t.User] = struct{}{}
+	}
+
+	return nil
+}
+
+// Delete deletes the tuples. Missing tuples are ignored.
+func (s *Store) Delete(tuples ...Tuple) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range tuples {
+		key := tupleKey(openfga.EntityString(t.Object), openfga.EntityString(t.Relation))
+
+		delete(s.tuples[key], t.User)
+
+		if len(s.tuples[key]) == 0 {
+			delete(s.tuples, key)
+		}
+	}
+
+	return nil
+}
+
+// Allowed returns true if the user has the relation on the object.
+// The contextual tuples of the context are used in addition to the stored tuples.
+func (s *Store) Allowed(ctx context.Context, user openfga.User, relation openfga.Relation, object openfga.Object) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.check(ctx, user, openfga.EntityString(relation), openfga.EntityString(object), 0)
+}
+
+// ListObjects returns the objects of the type on which the user has the relation.
+// Only objects of stored or contextual tuples can have a relation, so these are checked.
+func (s *Store) ListObjects(ctx context.Context, user openfga.User, relation openfga.Relation, typ string) ([]openfga.Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.relation(typ, openfga.EntityString(relation)); err != nil {
+		return nil, err
+	}
+
+	candidates := map[string]struct{}{}
+
+	for key := range s.tuples {
+		object, _, _ := strings.Cut(key, UsersetSeparator)
+		candidates[object] = struct{}{}
+	}
+
+	for _, t := range openfga.GetContextualTuples(ctx) {
+		candidates[openfga.EntityString(t.Object)] = struct{}{}
+	}
+
+	objects := []openfga.Object{}
+
+	for _, object := range slices.Sorted(maps.Keys(candidates)) {
+		if objectType(object) != typ {
+			continue
+		}
+
+		ok, err := s.check(ctx, user, openfga.EntityString(relation), object, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			objects = append(objects, openfga.Object(object))
+		}
+	}
+
+	return objects, nil
+}
+
+func (s *Store) check(ctx context.Context, user openfga.User, relation, object string, depth int) (bool, error) {
+	if depth >= s.opts.MaxDepth {
+		return false, ErrResolutionDepthExceeded
+	}
+
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	rewrite, err := s.relation(objectType(object), relation)
+	if err != nil {
+		return false, err
+	}
+
+	return s.eval(ctx, user, relation, object, rewrite, depth+1)
+}
+
+// nolint:gocyclo
+func (s *Store) eval(ctx context.Context, user openfga.User, relation, object string, rewrite fgasdk.Userset, depth int) (bool, error) {
+	switch {
+	case rewrite.This != nil:
+		return s.direct(ctx, user, relation, object, depth)
+	case rewrite.ComputedUserset != nil:
+		return s.check(ctx, user, rewrite.ComputedUserset.GetRelation(), object, depth)
+	case rewrite.TupleToUserset != nil:
+		tupleset := rewrite.TupleToUserset.Tupleset.GetRelation()
+		computed := rewrite.TupleToUserset.ComputedUserset.GetRelation()
+
+		for parent := range s.users(ctx, object, tupleset) {
+			// OpenFGA skips parents that do not define the computed relation.
+			if _, err := s.relation(objectType(openfga.EntityString(parent)), computed); err != nil {
+				continue
+			}
+
+			ok, err := s.check(ctx, user, computed, openfga.EntityString(parent), depth)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+
+		return false, nil
+	case rewrite.Union != nil:
+		for _, child := range rewrite.Union.Child {
+			ok, err := s.eval(ctx, user, relation, object, child, depth)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+
+		return false, nil
+	case rewrite.Intersection != nil:
+		for _, child := range rewrite.Intersection.Child {
+			ok, err := s.eval(ctx, user, relation, object, child, depth)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+
+		return len(rewrite.Intersection.Child) > 0, nil
+	case rewrite.Difference != nil:
+		ok, err := s.eval(ctx, user, relation, object, rewrite.Difference.Base, depth)
+		if err != nil || !ok {
+			return false, err
+		}
+
+		ok, err = s.eval(ctx, user, relation, object, rewrite.Difference.Subtract, depth)
+		if err != nil {
+			return false, err
+		}
+
+		return !ok, nil
+	}
+
+	return false, fmt.Errorf("memory: unsupported rewrite of %s#%s", objectType(object), relation)
+}
+
+func (s *Store) direct(ctx context.Context, user openfga.User, relation, object string, depth int) (bool, error) {
+	users := s.users(ctx, object, relation)
+
+	if _, ok := users[user]; ok {
+		return true, nil
+	}
+
+	if _, ok := users[openfga.User(objectType(openfga.EntityString(user))+openfga.DefaultNamespaceSeparator+Wildcard)]; ok {
+		return true, nil
+	}
+
+	for u := range users {
+		obj, rel, ok := strings.Cut(openfga.EntityString(u), UsersetSeparator)
+		if !ok {
+			continue
+		}
+
+		allowed, err := s.check(ctx, user, rel, obj, depth)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+
+	return false, nil
+}
+
+// users returns the users of the relation on the object including the contextual tuples.
+func (s *Store) users(ctx context.Context, object, relation string) map[openfga.User]struct{} {
+	stored := s.tuples[tupleKey(object, relation)]
+
+	contextual := openfga.GetContextualTuples(ctx)
+	if len(contextual) == 0 {
+		return stored
+	}
+
+	users := make(map[openfga.User]struct{}, len(stored))
+	for u := range stored {
+		users[u] = struct{}{}
+	}
+
+	for _, t := range contextual {
+		if openfga.EntityString(t.Object) == object && openfga.EntityString(t.Relation) == relation {
+			users[t.User] = struct{}{}
+		}
+	}
+
+	return users
+}
+
+func (s *Store) relation(typ, relation string) (fgasdk.Userset, error) {
+	td, ok := s.types[typ]
+	if !ok {
+		return fgasdk.Userset{}, fmt.Errorf("%w: %s", ErrUnknownType, typ)
+	}
+
+	rewrite, ok := td.GetRelations()[relation]
+	if !ok {
+		return fgasdk.Userset{}, fmt.Errorf("%w: %s#%s", ErrUnknownRelation, typ, relation)
+	}
+
+	return rewrite, nil
+}
+
+func (s *Store) validateTuple(t Tuple) error {
+	typ := objectType(openfga.EntityString(t.Object))
+
+	rewrite, err := s.relation(typ, openfga.EntityString(t.Relation))
+	if err != nil {
+		return err
+	}
+
+	if !directlyAssignable(rewrite) {
+		return fmt.Errorf("%w: %s#%s is not directly assignable", ErrInvalidTuple, typ, t.Relation)
+	}
+
+	td := s.types[typ]
+	metadata := td.GetMetadata()
+
+	meta, ok := metadata.GetRelations()[openfga.EntityString(t.Relation)]
+	if !ok || len(meta.GetDirectlyRelatedUserTypes()) == 0 {
+		return nil
+	}
+
+	userType, id, _ := strings.Cut(openfga.EntityString(t.User), openfga.DefaultNamespaceSeparator)
+	id, userRelation, isUserset := strings.Cut(id, UsersetSeparator)
+
+	for _, ref := range meta.GetDirectlyRelatedUserTypes() {
+		switch {
+		case ref.Type != userType:
+			continue
+		case isUserset && ref.GetRelation() == userRelation:
+			return nil
+		case !isUserset && id == Wildcard && ref.Wildcard != nil:
+			return nil
+		case !isUserset && id != Wildcard && ref.Relation == nil && ref.Wildcard == nil:
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s is not allowed for %s#%s", ErrInvalidTuple, t.User, typ, t.Relation)
+}
+
+func directlyAssignable(rewrite fgasdk.Userset) bool {
+	switch {
+	case rewrite.This != nil:
+		return true
+	case rewrite.Union != nil:
+		for _, child := range rewrite.Union.Child {
+			if directlyAssignable(child) {
+				return true
+			}
+		}
+	case rewrite.Difference != nil:
+		return directlyAssignable(rewrite.Difference.Base)
+	}
+
+	return false
+}
+
+func objectType(object string) string {
+	typ, _, _ := strings.Cut(object, openfga.DefaultNamespaceSeparator)
+
+	return typ
+}
+
+func tupleKey(object, relation string) string {
+	return object + UsersetSeparator + relation
+}
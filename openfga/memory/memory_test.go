@@ -0,0 +1,135 @@
+package memory_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeiss/fiber-authz/openfga"
+	"github.com/zeiss/fiber-authz/openfga/memory"
+)
+
+const model = `{
+  "schema_version": "1.1",
+  "type_definitions": [
+    {"type": "user"},
+    {
+      "type": "group",
+      "relations": {"member": {"this": {}}},
+      "metadata": {"relations": {"member": {"directly_related_user_types": [{"type": "user"}]}}}
+    },
+    {
+      "type": "document",
+      "relations": {
+        "owner": {"this": {}},
+        "blocked": {"this": {}},
+        "viewer": {
+          "difference": {
+            "base": {"union": {"child": [{"this": {}}, {"computedUserset": {"relation": "owner"}}]}},
+            "subtract": {"computedUserset": {"relation": "blocked"}}
+          }
+        },
+        "auditor": {
+          "intersection": {"child": [{"computedUserset": {"relation": "viewer"}}, {"computedUserset": {"relation": "owner"}}]}
+        }
+      },
+      "metadata": {"relations": {
+        "owner": {"directly_related_user_types": [{"type": "user"}]},
+        "blocked": {"directly_related_user_types": [{"type": "user"}]},
+        "viewer": {"directly_related_user_types": [{"type": "user"}, {"type": "user", "wildcard": {}}, {"type": "group", "relation": "member"}]}
+      }}
+    }
+  ]
+}`
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	store, err := memory.Parse([]byte(model))
+	require.NoError(t, err)
+
+	err = store.Write(
+		memory.Tuple{User: "user:alice", Relation: "owner", Object: "document:1"},
+		memory.Tuple{User: "user:bob", Relation: "member", Object: "group:eng"},
+		memory.Tuple{User: "group:eng#member", Relation: "viewer", Object: "document:1"},
+		memory.Tuple{User: "user:*", Relation: "viewer", Object: "document:2"},
+		memory.Tuple{User: "user:carol", Relation: "member", Object: "group:eng"},
+		memory.Tuple{User: "user:carol", Relation: "blocked", Object: "document:1"},
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		user     openfga.User
+		relation openfga.Relation
+		object   openfga.Object
+		allowed  bool
+	}{
+		{name: "direct", user: "user:alice", relation: "owner", object: "document:1", allowed: true},
+		{name: "computed userset", user: "user:alice", relation: "viewer", object: "document:1", allowed: true},
+		{name: "userset", user: "user:bob", relation: "viewer", object: "document:1", allowed: true},
+		{name: "wildcard", user: "user:dave", relation: "viewer", object: "document:2", allowed: true},
+		{name: "exclusion", user: "user:carol", relation: "viewer", object: "document:1"},
+		{name: "intersection", user: "user:alice", relation: "auditor", object: "document:1", allowed: true},
+		{name: "intersection denied", user: "user:bob", relation: "auditor", object: "document:1"},
+		{name: "no relation", user: "user:dave", relation: "viewer", object: "document:1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := store.Allowed(context.Background(), tt.user, tt.relation, tt.object)
+			require.NoError(t, err)
+			require.Equal(t, tt.allowed, allowed)
+		})
+	}
+}
+
+func TestStoreExample(t *testing.T) {
+	t.Parallel()
+
+	b, err := os.ReadFile("../../examples/fga/example.json")
+	require.NoError(t, err)
+
+	store, err := memory.Parse(b)
+	require.NoError(t, err)
+
+	tuples := []memory.Tuple{
+		{User: "team:zeiss", Relation: "team", Object: "workload:foo"},
+		{User: "user:katallaxie", Relation: "editor", Object: "team:zeiss"},
+	}
+	require.NoError(t, store.Write(tuples...))
+
+	allowed, err := store.Allowed(context.Background(), "user:katallaxie", "can_write", "workload:foo")
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	require.NoError(t, store.Delete(tuples[1]))
+
+	allowed, err = store.Allowed(context.Background(), "user:katallaxie", "can_write", "workload:foo")
+	require.NoError(t, err)
+	require.False(t, allowed)
+}
+
+func TestStoreInvalidTuple(t *testing.T) {
+	t.Parallel()
+
+	store, err := memory.Parse([]byte(model))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		tuple memory.Tuple
+	}{
+		{name: "unknown type", tuple: memory.Tuple{User: "user:alice", Relation: "owner", Object: "folder:1"}},
+		{name: "unknown relation", tuple: memory.Tuple{User: "user:alice", Relation: "editor", Object: "document:1"}},
+		{name: "not assignable", tuple: memory.Tuple{User: "user:alice", Relation: "auditor", Object: "document:1"}},
+		{name: "wrong user type", tuple: memory.Tuple{User: "group:eng", Relation: "owner", Object: "document:1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, store.Write(tt.tuple))
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Package cmd provides commands for OpenFGA models.
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/spf13/cobra"
+	"github.com/zeiss/fiber-authz/openfga"
+	"github.com/zeiss/fiber-authz/openfga/model"
+)
+
+// GenerateFlags are the flags of the generate command.
+type GenerateFlags struct {
+	// Spec is the path of the OpenAPI document.
+	Spec string
+	// Extension is the name of the extension.
+	Extension string
+	// DSL is the path of the model in the DSL format.
+	DSL string
+	// JSON is the path of the model in the JSON format.
+	JSON string
+}
+
+// NewGenerateCmd returns a command that generates a starter model from an OpenAPI document.
+// The model is printed in the DSL format if no output is set,
+// the operations without an extension are reported on stderr.
+func NewGenerateCmd() *cobra.Command {
+	flags := &GenerateFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate an OpenFGA model from an OpenAPI document",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runGenerate(cmd, flags)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.Spec, "spec", "", "OpenAPI document")
+	cmd.Flags().StringVar(&flags.Extension, "extension", openfga.DefaultExtensionName, "Extension name")
+	cmd.Flags().StringVar(&flags.DSL, "dsl", "", "Output path of the model in the DSL format")
+	cmd.Flags().StringVar(&flags.JSON, "json", "", "Output path of the model in the JSON format")
+
+	_ = cmd.MarkFlagRequired("spec")
+
+	return cmd
+}
+
+func runGenerate(cmd *cobra.Command, flags *GenerateFlags) error {
+	doc, err := openapi3.NewLoader().LoadFromFile(flags.Spec)
+	if err != nil {
+		return err
+	}
+
+	skeleton, err := model.Generate(doc, flags.Extension)
+	if err != nil {
+		return err
+	}
+
+	dsl, err := skeleton.DSL()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(skeleton.Model, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if flags.DSL == "" && flags.JSON == "" {
+		fmt.Fprint(cmd.OutOrStdout(), dsl)
+	}
+
+	if flags.DSL != "" {
+		if err := os.WriteFile(flags.DSL, []byte(dsl), 0o600); err != nil {
+			return err
+		}
+	}
+
+	if flags.JSON != "" {
+		if err := os.WriteFile(flags.JSON, b, 0o600); err != nil {
+			return err
+		}
+	}
+
+	for _, op := range skeleton.Unannotated {
+		fmt.Fprintf(cmd.ErrOrStderr(), "unannotated: %s\n", op)
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mitchellh/mapstructure"
+	fgasdk "github.com/openfga/go-sdk"
+	"github.com/zeiss/fiber-authz/openfga"
+)
+
+// SchemaVersion is the schema version of generated models.
+const SchemaVersion = "1.1"
+
+// DefaultUserType is the user type of checks without a user namespace.
+const DefaultUserType = "user"
+
+// Skeleton is a starter model that is generated from an OpenAPI document.
+type Skeleton struct {
+	// Model is the generated model.
+	Model *Model
+	// Unannotated are the operations without an extension that are not public, e.g. "GET /health".
+	Unannotated []string
+}
+
+// Generate generates a starter model from the extensions of all operations of the document.
+// Every object namespace becomes a type with the relations that are referenced by the extensions,
+// the relations are directly assignable to the user types of the checks.
+func Generate(doc *openapi3.T, name ...string) (*Skeleton, error) {
+	ext := openfga.DefaultExtensionName
+	if len(name) > 0 {
+		ext = name[0]
+	}
+
+	skeleton := &Skeleton{Unannotated: []string{}}
+	relations := map[string]map[string]map[string]struct{}{}
+
+	var errs error
+
+	if doc.Paths != nil {
+		for _, path := range slices.Sorted(maps.Keys(doc.Paths.Map())) {
+			ops := doc.Paths.Value(path).Operations()
+
+			for _, method := range slices.Sorted(maps.Keys(ops)) {
+				if openfga.OperationStatus(ops[method], ext) == openfga.CoverageUnannotated {
+					skeleton.Unannotated = append(skeleton.Unannotated, fmt.Sprintf("%s %s", method, path))
+				}
+
+				v, ok := ops[method].Extensions[ext]
+				if !ok {
+					continue
+				}
+
+				opts := &openfga.OasFGAAuthzOptions{}
+				if err := mapstructure.Decode(v, opts); err != nil {
+					errs = errors.Join(errs, fmt.Errorf("%s %s: %w", method, path, err))
+					continue
+				}
+
+				collectRelations(relations, opts)
+			}
+		}
+	}
+
+	if errs != nil {
+		return nil, errs
+	}
+
+	skeleton.Model = skeletonModel(relations)
+
+	return skeleton, nil
+}
+
+// DSL returns the model of the skeleton in the OpenFGA DSL.
+func (s *Skeleton) DSL() (string, error) {
+	return DSL(s.Model)
+}
+
+// collectRelations adds the user types of the relations of the object types.
+func collectRelations(relations map[string]map[string]map[string]struct{}, opts *openfga.OasFGAAuthzOptions) {
+	if opts.IsGroup() {
+		for i := range opts.All {
+			collectRelations(relations, &opts.All[i])
+		}
+
+		for i := range opts.Any {
+			collectRelations(relations, &opts.Any[i])
+		}
+
+		return
+	}
+
+	user := opts.User.Namespace
+	if user == "" {
+		user = DefaultUserType
+	}
+
+	add := func(typ, relation string) {
+		if typ == "" || relation == "" {
+			return
+		}
+
+		if _, ok := relations[typ]; !ok {
+			relations[typ] = map[string]map[string]struct{}{}
+		}
+
+		if _, ok := relations[typ][relation]; !ok {
+			relations[typ][relation] = map[string]struct{}{}
+		}
+
+		relations[typ][relation][user] = struct{}{}
+	}
+
+	if _, ok := relations[user]; !ok {
+		relations[user] = map[string]map[string]struct{}{}
+	}
+
+	add(opts.Object.Namespace, opts.Relation.Name)
+
+	for _, t := range opts.ContextualTuples {
+		add(t.Namespace, t.Relation)
+	}
+}
+
+func skeletonModel(relations map[string]map[string]map[string]struct{}) *Model {
+	m := &Model{SchemaVersion: SchemaVersion, TypeDefinitions: []fgasdk.TypeDefinition{}}
+
+	for _, typ := range slices.Sorted(maps.Keys(relations)) {
+		td := fgasdk.TypeDefinition{Type: typ}
+
+		if len(relations[typ]) > 0 {
+			rewrites := map[string]fgasdk.Userset{}
+			metadata := map[string]fgasdk.RelationMetadata{}
+
+			for relation, users := range relations[typ] {
+				refs := []fgasdk.RelationReference{}
+				for _, user := range slices.Sorted(maps.Keys(users)) {
+					refs = append(refs, fgasdk.RelationReference{Type: user})
+				}
+
+				rewrites[relation] = fgasdk.Userset{This: &map[string]interface{}{}}
+				metadata[relation] = fgasdk.RelationMetadata{DirectlyRelatedUserTypes: &refs}
+			}
+
+			td.Relations = &rewrites
+			td.Metadata = &fgasdk.Metadata{Relations: &metadata}
+		}
+
+		m.TypeDefinitions = append(m.TypeDefinitions, td)
+	}
+
+	return m
+}
@@ -0,0 +1,190 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	fgasdk "github.com/openfga/go-sdk"
+	"github.com/openfga/language/pkg/go/transformer"
+)
+
+// Model is an OpenFGA authorization model.
+type Model = fgasdk.WriteAuthorizationModelRequest
+
+// Parse parses a model in the OpenFGA DSL or JSON format.
+func Parse(b []byte) (*Model, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		return ParseJSON(b)
+	}
+
+	return ParseDSL(string(b))
+}
+
+// ParseDSL parses a model in the OpenFGA DSL.
+func ParseDSL(dsl string) (*Model, error) {
+	s, err := transformer.TransformDSLToJSON(dsl)
+	if err != nil {
+		return nil, fmt.Errorf("model: parsing dsl: %w", err)
+	}
+
+	return ParseJSON([]byte(s))
+}
+
+// ParseJSON parses a model in the OpenFGA JSON format.
+func ParseJSON(b []byte) (*Model, error) {
+	var m Model
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("model: parsing json: %w", err)
+	}
+
+	return &m, nil
+}
+
+// DSL returns the model in the OpenFGA DSL.
+func DSL(m *Model) (string, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+
+	s, err := transformer.TransformJSONStringToDSL(string(b))
+	if err != nil {
+		return "", fmt.Errorf("model: transforming to dsl: %w", err)
+	}
+
+	return *s, nil
+}
+
+// Validate validates the type and relation references of the model.
+// It returns all errors that are found.
+func Validate(m *Model) error {
+	types := Types(m)
+
+	var errs error
+
+	if len(types) != len(m.TypeDefinitions) {
+		errs = errors.Join(errs, errors.New("model: duplicate type definitions"))
+	}
+
+	for _, td := range m.TypeDefinitions {
+		metadata := td.GetMetadata()
+		meta := metadata.GetRelations()
+
+		for name, rewrite := range td.GetRelations() {
+			if err := validateRewrite(types, td.Type, rewrite); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("model: %s#%s: %w", td.Type, name, err))
+			}
+
+			rm := meta[name]
+			for _, ref := range rm.GetDirectlyRelatedUserTypes() {
+				if err := validateReference(types, m.GetConditions(), ref); err != nil {
+					errs = errors.Join(errs, fmt.Errorf("model: %s#%s: %w", td.Type, name, err))
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// Types returns the type definitions by type.
+func Types(m *Model) map[string]fgasdk.TypeDefinition {
+	types := make(map[string]fgasdk.TypeDefinition, len(m.TypeDefinitions))
+
+	for _, td := range m.TypeDefinitions {
+		types[td.Type] = td
+	}
+
+	return types
+}
+
+// HasRelation returns true if the type defines the relation.
+func HasRelation(types map[string]fgasdk.TypeDefinition, typ, relation string) bool {
+	td, ok := types[typ]
+	if !ok {
+		return false
+	}
+
+	_, ok = td.GetRelations()[relation]
+
+	return ok
+}
+
+func validateRewrite(types map[string]fgasdk.TypeDefinition, typ string, rewrite fgasdk.Userset) error {
+	switch {
+	case rewrite.This != nil:
+		return nil
+	case rewrite.ComputedUserset != nil:
+		if r := rewrite.ComputedUserset.GetRelation(); !HasRelation(types, typ, r) {
+			return fmt.Errorf("unknown relation %s", r)
+		}
+
+		return nil
+	case rewrite.TupleToUserset != nil:
+		tupleset := rewrite.TupleToUserset.Tupleset.GetRelation()
+		if !HasRelation(types, typ, tupleset) {
+			return fmt.Errorf("unknown tupleset relation %s", tupleset)
+		}
+
+		computed := rewrite.TupleToUserset.ComputedUserset.GetRelation()
+		if !tuplesetDefines(types, typ, tupleset, computed) {
+			return fmt.Errorf("no type of tupleset relation %s defines relation %s", tupleset, computed)
+		}
+
+		return nil
+	case rewrite.Union != nil:
+		return validateRewrites(types, typ, rewrite.Union.Child)
+	case rewrite.Intersection != nil:
+		return validateRewrites(types, typ, rewrite.Intersection.Child)
+	case rewrite.Difference != nil:
+		return validateRewrites(types, typ, []fgasdk.Userset{rewrite.Difference.Base, rewrite.Difference.Subtract})
+	}
+
+	return errors.New("empty rewrite")
+}
+
+// tuplesetDefines returns true if a directly related type of the tupleset relation defines the relation.
+func tuplesetDefines(types map[string]fgasdk.TypeDefinition, typ, tupleset, relation string) bool {
+	td := types[typ]
+	metadata := td.GetMetadata()
+	meta := metadata.GetRelations()
+	rm := meta[tupleset]
+
+	for _, ref := range rm.GetDirectlyRelatedUserTypes() {
+		if HasRelation(types, ref.Type, relation) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateRewrites(types map[string]fgasdk.TypeDefinition, typ string, rewrites []fgasdk.Userset) error {
+	var errs error
+
+	for _, r := range rewrites {
+		errs = errors.Join(errs, validateRewrite(types, typ, r))
+	}
+
+	return errs
+}
+
+func validateReference(types map[string]fgasdk.TypeDefinition, conditions map[string]fgasdk.Condition, ref fgasdk.RelationReference) error {
+	if _, ok := types[ref.Type]; !ok {
+		return fmt.Errorf("unknown type %s", ref.Type)
+	}
+
+	if r := ref.GetRelation(); r != "" && !HasRelation(types, ref.Type, r) {
+		return fmt.Errorf("unknown relation %s#%s", ref.Type, r)
+	}
+
+	if c := ref.GetCondition(); c != "" {
+		if _, ok := conditions[c]; !ok {
+			return fmt.Errorf("unknown condition %s", c)
+		}
+	}
+
+	return nil
+}
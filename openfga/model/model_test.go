@@ -0,0 +1,235 @@
+package model_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/require"
+	"github.com/zeiss/fiber-authz/openfga/model"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"example.fga", "example.json"} {
+		t.Run(name, func(t *testing.T) {
+			b, err := os.ReadFile("../../examples/fga/" + name)
+			require.NoError(t, err)
+
+			m, err := model.Parse(b)
+			require.NoError(t, err)
+			require.NoError(t, model.Validate(m))
+			require.Len(t, m.TypeDefinitions, 6)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		dsl  string
+		err  string
+	}{
+		{
+			name: "unknown relation",
+			dsl: `model
+  schema 1.1
+type user
+type team
+  relations
+    define viewer: editor`,
+			err: "unknown relation editor",
+		},
+		{
+			name: "unknown type",
+			dsl: `model
+  schema 1.1
+type team
+  relations
+    define member: [user]`,
+			err: "unknown type user",
+		},
+		{
+			name: "unknown userset relation",
+			dsl: `model
+  schema 1.1
+type user
+type team
+  relations
+    define member: [user]
+type workload
+  relations
+    define viewer: [team#admin]`,
+			err: "unknown relation team#admin",
+		},
+		{
+			name: "tupleset types without the computed relation",
+			dsl: `model
+  schema 1.1
+type user
+type folder
+  relations
+    define owner: [user]
+type document
+  relations
+    define parent: [folder]
+    define viewer: [user] or viewer from parent`,
+			err: "no type of tupleset relation parent defines relation viewer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := model.ParseDSL(tt.dsl)
+			if err == nil {
+				err = model.Validate(m)
+			}
+
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestValidateTupleToUserset(t *testing.T) {
+	t.Parallel()
+
+	m, err := model.ParseDSL(`model
+  schema 1.1
+type user
+type team
+  relations
+    define member: [user]
+type folder
+  relations
+    define viewer: [user]
+type document
+  relations
+    define parent: [team, folder]
+    define viewer: [user] or viewer from parent`)
+	require.NoError(t, err)
+	require.NoError(t, model.Validate(m))
+}
+
+func TestValidateSpec(t *testing.T) {
+	t.Parallel()
+
+	b, err := os.ReadFile("../../examples/fga/example.fga")
+	require.NoError(t, err)
+
+	m, err := model.Parse(b)
+	require.NoError(t, err)
+
+	spec := `
+openapi: 3.0.0
+info:
+  title: test
+  version: 1.0.0
+paths:
+  /teams/{teamId}:
+    get:
+      x-fiber-authz-fga:
+        user:
+          namespace: user
+        relation:
+          name: can_read
+        object:
+          namespace: team
+      responses:
+        "200":
+          description: ok
+  /workloads/{id}:
+    get:
+      x-fiber-authz-fga:
+        user:
+          namespace: user
+        relation:
+          name: can_read
+        object:
+          namespace: workload
+      responses:
+        "200":
+          description: ok
+    delete:
+      x-fiber-authz-fga:
+        user:
+          namespace: user
+        relation:
+          name: can_delete
+        object:
+          namespace: system
+      responses:
+        "200":
+          description: ok
+`
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+
+	err = model.ValidateSpec(m, doc)
+	require.ErrorContains(t, err, "GET /teams/{teamId}: unknown relation team#can_read")
+	require.ErrorContains(t, err, `DELETE /workloads/{id}: unknown object type "system"`)
+	require.NotContains(t, err.Error(), "GET /workloads/{id}")
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	spec := `
+openapi: 3.0.0
+info:
+  title: test
+  version: 1.0.0
+paths:
+  /health:
+    get:
+      responses:
+        "200":
+          description: ok
+  /teams/{teamId}:
+    get:
+      x-fiber-authz-fga:
+        relation:
+          name: viewer
+        object:
+          namespace: team
+      responses:
+        "200":
+          description: ok
+    put:
+      x-fiber-authz-fga:
+        any:
+          - relation:
+              name: editor
+            object:
+              namespace: team
+          - user:
+              namespace: application
+            relation:
+              name: editor
+            object:
+              namespace: team
+      responses:
+        "200":
+          description: ok
+`
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+
+	skeleton, err := model.Generate(doc)
+	require.NoError(t, err)
+	require.Equal(t, []string{"GET /health"}, skeleton.Unannotated)
+	require.NoError(t, model.Validate(skeleton.Model))
+
+	dsl, err := skeleton.DSL()
+	require.NoError(t, err)
+	require.Contains(t, dsl, "define editor: [application, user]")
+	require.Contains(t, dsl, "define viewer: [user]")
+
+	m, err := model.ParseDSL(dsl)
+	require.NoError(t, err)
+	require.NoError(t, model.ValidateSpec(m, doc))
+}
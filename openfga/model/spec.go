@@ -0,0 +1,89 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mitchellh/mapstructure"
+	fgasdk "github.com/openfga/go-sdk"
+	"github.com/zeiss/fiber-authz/openfga"
+)
+
+// ValidateSpec cross-checks the extensions of all operations against the model.
+// It returns an error for unknown user and object types and for relations
+// that are not defined on the object type.
+func ValidateSpec(m *Model, doc *openapi3.T, name ...string) error {
+	ext := openfga.DefaultExtensionName
+	if len(name) > 0 {
+		ext = name[0]
+	}
+
+	if doc.Paths == nil {
+		return nil
+	}
+
+	types := Types(m)
+
+	var errs error
+
+	for path, pathItem := range doc.Paths.Map() {
+		for method, op := range pathItem.Operations() {
+			v, ok := op.Extensions[ext]
+			if !ok {
+				continue
+			}
+
+			opts := &openfga.OasFGAAuthzOptions{}
+			if err := mapstructure.Decode(v, opts); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("%s %s: %w", method, path, err))
+				continue
+			}
+
+			if err := validateOptions(types, opts); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("%s %s: %w", method, path, err))
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateOptions(types map[string]fgasdk.TypeDefinition, opts *openfga.OasFGAAuthzOptions) error {
+	var errs error
+
+	if opts.IsGroup() {
+		for i := range opts.All {
+			errs = errors.Join(errs, validateOptions(types, &opts.All[i]))
+		}
+
+		for i := range opts.Any {
+			errs = errors.Join(errs, validateOptions(types, &opts.Any[i]))
+		}
+
+		return errs
+	}
+
+	if ns := opts.User.Namespace; ns != "" {
+		if _, ok := types[ns]; !ok {
+			errs = errors.Join(errs, fmt.Errorf("unknown user type %s", ns))
+		}
+	}
+
+	for _, t := range opts.ContextualTuples {
+		if !HasRelation(types, t.Namespace, t.Relation) {
+			errs = errors.Join(errs, fmt.Errorf("unknown contextual tuple relation %s#%s", t.Namespace, t.Relation))
+		}
+	}
+
+	typ := opts.Object.Namespace
+	if _, ok := types[typ]; !ok {
+		return errors.Join(errs, fmt.Errorf("unknown object type %q", typ))
+	}
+
+	if !HasRelation(types, typ, opts.Relation.Name) {
+		errs = errors.Join(errs, fmt.Errorf("unknown relation %s#%s", typ, opts.Relation.Name))
+	}
+
+	return errs
+}
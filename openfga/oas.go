@@ -1,15 +1,25 @@
 package openfga
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3filter"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
 	"github.com/mitchellh/mapstructure"
 	middleware "github.com/oapi-codegen/fiber-middleware"
+	"github.com/zeiss/fiber-authz/oas"
+	"github.com/zeiss/fiber-authz/oas/oidc"
 )
 
 // DefaultExtensionName is the default extension name.
@@ -26,6 +36,12 @@ type OasFGAAuthzOptionComponent struct {
 	Name string `json:"name" mapstructure:"name"`
 	// Type is the type of the component.
 	Type string `json:"type" mapstructure:"type"`
+	// Value is the value of a static component.
+	Value string `json:"value" mapstructure:"value"`
+	// Default is used if the component has no value.
+	Default string `json:"default" mapstructure:"default"`
+	// Required returns a bad request if the component has no value.
+	Required bool `json:"required" mapstructure:"required"`
 }
 
 // OasFGAAuthzOption ...
@@ -40,6 +56,19 @@ type OasFGAAuthzOption struct {
 	Components []OasFGAAuthzOptionComponent `json:"components" mapstructure:"components"`
 	// AuthType is the auth type of the option.
 	AuthType string `json:"auth_type" mapstructure:"auth_type"`
+	// Claim is the claim of the OIDC token that is used as user id.
+	Claim string `json:"claim" mapstructure:"claim"`
+}
+
+// OasFGAAuthzContextualTuples are contextual tuples of the user of the check.
+// A tuple is added for each value of the claim, e.g. user:alice member team:admins for the groups claim.
+type OasFGAAuthzContextualTuples struct {
+	// Relation is the relation of the tuples.
+	Relation string `json:"relation" mapstructure:"relation"`
+	// Namespace is the namespace of the objects.
+	Namespace string `json:"namespace" mapstructure:"namespace"`
+	// Claim is the claim of the OIDC token with the object ids.
+	Claim string `json:"claim" mapstructure:"claim"`
 }
 
 // OasFGAAuthzOptions ...
@@ -50,6 +79,19 @@ type OasFGAAuthzOptions struct {
 	Relation OasFGAAuthzOption `json:"relation" mapstructure:"relation"`
 	// Object is the object option.
 	Object OasFGAAuthzOption `json:"object" mapstructure:"object"`
+	// All requires all checks to be allowed.
+	All []OasFGAAuthzOptions `json:"all,omitempty" mapstructure:"all"`
+	// Any requires one of the checks to be allowed.
+	Any []OasFGAAuthzOptions `json:"any,omitempty" mapstructure:"any"`
+	// ContextualTuples are added to the check.
+	ContextualTuples []OasFGAAuthzContextualTuples `json:"contextual_tuples,omitempty" mapstructure:"contextual_tuples"`
+	// Context is the condition context of the check by parameter name.
+	Context map[string]OasFGAAuthzOptionComponent `json:"context,omitempty" mapstructure:"context"`
+}
+
+// IsGroup returns true if the options are a group of checks.
+func (o *OasFGAAuthzOptions) IsGroup() bool {
+	return len(o.All) > 0 || len(o.Any) > 0
 }
 
 // OasFGABuilder ...
@@ -58,6 +100,17 @@ type OasFGABuilder interface {
 	BuildWithContext(ctx context.Context, input *openapi3filter.AuthenticationInput) (User, Relation, Object, error)
 }
 
+// OasFGAChecksBuilder builds the checks of an operation.
+type OasFGAChecksBuilder interface {
+	// BuildChecksWithContext builds a check or a group of checks with a context.
+	BuildChecksWithContext(ctx context.Context, input *openapi3filter.AuthenticationInput) (Checks, error)
+}
+
+var (
+	_ OasFGABuilder       = (*OasFGAAuthzBuilder)(nil)
+	_ OasFGAChecksBuilder = (*OasFGAAuthzBuilder)(nil)
+)
+
 // OasFGAAuthzBuilder ...
 type OasFGAAuthzBuilder struct {
 	opts OasFGAAuthzBuilderOpts
@@ -67,6 +120,8 @@ type OasFGAAuthzBuilder struct {
 type OasFGAAuthzBuilderOpts struct {
 	// PropertyName ...
 	PropertyName string
+	// UserProviders are the user providers by authentication type.
+	UserProviders UserProviders
 }
 
 // Configure sets the configuration for the builder.
@@ -94,7 +149,8 @@ func NewOasFGAAuthzBuilder(opts ...OasFGAAuthzBuilderOpt) *OasFGAAuthzBuilder {
 // DefaultOasFGAAuthzBuilderOpts ...
 func DefaultOasFGAAuthzBuilderOpts() OasFGAAuthzBuilderOpts {
 	return OasFGAAuthzBuilderOpts{
-		PropertyName: DefaultExtensionName,
+		PropertyName:  DefaultExtensionName,
+		UserProviders: DefaultUserProviders(),
 	}
 }
 
@@ -105,6 +161,13 @@ func WithOasFGAAuthzBuilderPropertyName(name string) OasFGAAuthzBuilderOpt {
 	}
 }
 
+// WithUserProvider registers the user provider for the authentication type.
+func WithUserProvider(authType string, provider UserProvider) OasFGAAuthzBuilderOpt {
+	return func(o *OasFGAAuthzBuilderOpts) {
+		o.UserProviders[authType] = provider
+	}
+}
+
 // BuildWithContext ...
 func (f *OasFGAAuthzBuilder) BuildWithContext(ctx context.Context, input *openapi3filter.AuthenticationInput) (User, Relation, Object, error) {
 	opts := &OasFGAAuthzOptions{}
@@ -119,30 +182,199 @@ func (f *OasFGAAuthzBuilder) BuildWithContext(ctx context.Context, input *openap
 		return NoopUser, NoopRelation, NoopObject, err
 	}
 
-	return BuildUser(ctx, input, opts), BuildRelation(ctx, input, opts), BuildObject(ctx, input, opts), nil
+	if opts.IsGroup() {
+		return NoopUser, NoopRelation, NoopObject, fmt.Errorf("%s has a group of checks", f.opts.PropertyName)
+	}
+
+	object, err := ResolveObject(ctx, input, opts)
+	if err != nil {
+		return NoopUser, NoopRelation, NoopObject, err
+	}
+
+	user, err := BuildUserWithProviders(ctx, input, opts, f.opts.UserProviders)
+	if err != nil {
+		return NoopUser, NoopRelation, NoopObject, err
+	}
+
+	return user, BuildRelation(ctx, input, opts), object, nil
 }
 
-// BuildUser is a function that builds a user.
-func BuildUser(ctx context.Context, input *openapi3filter.AuthenticationInput, opts *OasFGAAuthzOptions) User {
-	return NewUser(Namespace(opts.User.Namespace), OidcSubject(ctx))
+// BuildChecksWithContext builds a check or a group of checks.
+func (f *OasFGAAuthzBuilder) BuildChecksWithContext(ctx context.Context, input *openapi3filter.AuthenticationInput) (Checks, error) {
+	opts := &OasFGAAuthzOptions{}
+
+	ext, ok := input.RequestValidationInput.Route.Operation.Extensions[f.opts.PropertyName]
+	if !ok {
+		return Checks{}, ErrNoFGAAuthzBuilderExtensionFound
+	}
+
+	if err := mapstructure.Decode(ext, opts); err != nil {
+		return Checks{}, err
+	}
+
+	return BuildChecks(ctx, input, opts, f.opts.UserProviders)
+}
+
+// BuildChecks builds a check or a group of checks.
+func BuildChecks(ctx context.Context, input *openapi3filter.AuthenticationInput, opts *OasFGAAuthzOptions, providers UserProviders) (Checks, error) {
+	if !opts.IsGroup() {
+		object, err := ResolveObject(ctx, input, opts)
+		if err != nil {
+			return Checks{}, err
+		}
+
+		user, err := BuildUserWithProviders(ctx, input, opts, providers)
+		if err != nil {
+			return Checks{}, err
+		}
+
+		check := &Check{
+			User:             user,
+			Relation:         BuildRelation(ctx, input, opts),
+			Object:           object,
+			ContextualTuples: BuildContextualTuples(ctx, user, opts),
+		}
+
+		check.Context, err = BuildConditionContext(ctx, input, opts)
+		if err != nil {
+			return Checks{}, err
+		}
+
+		return Checks{Check: check}, nil
+	}
+
+	if len(opts.All) > 0 && len(opts.Any) > 0 {
+		return Checks{}, ErrMixedCombinators
+	}
+
+	group := Checks{Combinator: CombinatorAll}
+	children := opts.All
+
+	if len(opts.Any) > 0 {
+		group.Combinator = CombinatorAny
+		children = opts.Any
+	}
+
+	for i := range children {
+		checks, err := BuildChecks(ctx, input, &children[i], providers)
+		if err != nil {
+			return Checks{}, err
+		}
+
+		group.Checks = append(group.Checks, checks)
+	}
+
+	return group, nil
 }
 
-// Object ...
+// BuildObject builds the object from the components.
+// It returns NoopObject if a component cannot be resolved, see ResolveObject.
 func BuildObject(ctx context.Context, input *openapi3filter.AuthenticationInput, opts *OasFGAAuthzOptions) Object {
-	ss := []string{}
+	object, err := ResolveObject(ctx, input, opts)
+	if err != nil {
+		return NoopObject
+	}
+
+	return object
+}
+
+// ResolveObject builds the object from the components.
+// It returns an error if a required component is missing or the body is not valid JSON.
+func ResolveObject(ctx context.Context, input *openapi3filter.AuthenticationInput, opts *OasFGAAuthzOptions) (Object, error) {
+	ss := make([]string, 0, len(opts.Object.Components))
 
 	for _, c := range opts.Object.Components {
-		switch c.In {
-		case "path":
-			ss = append(ss, PathParams(input.RequestValidationInput.PathParams, c.Name))
-		case "query":
-			ss = append(ss, QueryParams(input.RequestValidationInput.GetQueryParams(), c.Name))
-		default:
-			ss = append(ss, "")
+		s, err := ComponentValue(ctx, input, c)
+		if err != nil {
+			return NoopObject, err
+		}
+
+		ss = append(ss, s)
+	}
+
+	return NewObject(Namespace(opts.Object.Namespace), String(opts.Object.Name), Join(opts.Object.Separator, ss...)), nil
+}
+
+// BuildContextualTuples builds the contextual tuples of the user from the claims.
+func BuildContextualTuples(ctx context.Context, user User, opts *OasFGAAuthzOptions) []Tuple {
+	tuples := []Tuple{}
+
+	for _, t := range opts.ContextualTuples {
+		for _, id := range ClaimValues(ctx, t.Claim) {
+			tuples = append(tuples, Tuple{
+				User:     user,
+				Relation: NewRelation(String(t.Relation)),
+				Object:   NewObject(Namespace(t.Namespace), String(id)),
+			})
 		}
 	}
 
-	return NewObject(Namespace(opts.Object.Namespace), String(opts.Object.Name), Join(opts.Object.Separator, ss...))
+	return tuples
+}
+
+// BuildConditionContext builds the condition context from the components.
+// Components without a value are left out.
+func BuildConditionContext(ctx context.Context, input *openapi3filter.AuthenticationInput, opts *OasFGAAuthzOptions) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	for name, c := range opts.Context {
+		s, err := ComponentValue(ctx, input, c)
+		if err != nil {
+			return nil, err
+		}
+
+		if s != "" {
+			values[name] = s
+		}
+	}
+
+	return values, nil
+}
+
+// ComponentValue returns the value of the component.
+// The default is used if the component has no value.
+func ComponentValue(ctx context.Context, input *openapi3filter.AuthenticationInput, c OasFGAAuthzOptionComponent) (string, error) {
+	var s string
+
+	req := input.RequestValidationInput.Request
+
+	switch c.In {
+	case InPath:
+		s = PathParams(input.RequestValidationInput.PathParams, c.Name)
+	case InQuery:
+		s = QueryParams(input.RequestValidationInput.GetQueryParams(), c.Name)
+	case InHeader:
+		s = req.Header.Get(c.Name)
+	case InCookie:
+		if cookie, err := req.Cookie(c.Name); err == nil {
+			s = cookie.Value
+		}
+	case InBody:
+		v, err := BodyPointer(req, c.Name)
+		if err != nil {
+			return "", err
+		}
+
+		s = v
+	case InClaim:
+		s = Claim(ctx, c.Name)
+	case InValue:
+		s = c.Value
+	case InIP:
+		s = ClientIP(ctx, req)
+	case InTime:
+		s = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	if s == "" {
+		s = c.Default
+	}
+
+	if s == "" && c.Required {
+		return "", fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("missing required %s component %q", c.In, c.Name))
+	}
+
+	return s, nil
 }
 
 // BuildRelation ...
@@ -150,11 +382,18 @@ func BuildRelation(ctx context.Context, input *openapi3filter.AuthenticationInpu
 	return NewRelation(Namespace(opts.Relation.Namespace), String(opts.Relation.Name))
 }
 
+// ErrUnannotatedOperation is returned in fail-closed mode for operations without the extension.
+var ErrUnannotatedOperation = fiber.NewError(fiber.StatusForbidden, "operation has no authorization")
+
 // OasAuthenticateOpts is a configuration for the authenticator.
 type OasAuthenticateOpts struct {
 	Checker Checker
 	Builder OasFGABuilder
 	Next    OasAuthenticateNextFunc
+	// Extension is the name of the extension.
+	Extension string
+	// FailClosed denies operations without the extension that are not marked as public.
+	FailClosed bool
 }
 
 // OasAuthenticateNextFunc is a function that determines if the next function should be called.
@@ -182,8 +421,27 @@ type OasAuthenticateOpt func(*OasAuthenticateOpts)
 // OasDefaultAuthenticateOpts ...
 func OasDefaultAuthenticateOpts() OasAuthenticateOpts {
 	return OasAuthenticateOpts{
-		Builder: NewOasFGAAuthzBuilder(),
-		Next:    DefaultOasAuthenticateNextFunc(DefaultExtensionName),
+		Builder:   NewOasFGAAuthzBuilder(),
+		Next:      DefaultOasAuthenticateNextFunc(DefaultExtensionName),
+		Extension: DefaultExtensionName,
+	}
+}
+
+// WithExtension sets the name of the extension of the authenticator.
+// An OasFGAAuthzBuilder, e.g. the default builder, reads the checks from the same extension.
+func WithExtension(name string) OasAuthenticateOpt {
+	return func(o *OasAuthenticateOpts) {
+		o.Extension = name
+		o.Next = DefaultOasAuthenticateNextFunc(name)
+	}
+}
+
+// WithFailClosed denies operations without the extension,
+// unless they are marked as public with x-fiber-authz-public: true.
+// It only applies to operations with a security requirement, use FailClosed for all operations.
+func WithFailClosed() OasAuthenticateOpt {
+	return func(o *OasAuthenticateOpts) {
+		o.FailClosed = true
 	}
 }
 
@@ -202,38 +460,94 @@ func WithChecker(checker Checker) OasAuthenticateOpt {
 }
 
 // OasAuthenticate is an authentication function that uses the FGA authz builder and checker.
+// The status of its errors is recorded with oas.SetStatus, e.g. 401 for ErrNoIdentity,
+// so authz.NewOpenAPIErrorHandler answers with it instead of 400.
 func OasAuthenticate(opts ...OasAuthenticateOpt) openapi3filter.AuthenticationFunc {
 	options := OasDefaultAuthenticateOpts()
 	options.Configure(opts...)
 
+	// The builder is copied, as it may be shared with other authenticators.
+	if builder, ok := options.Builder.(*OasFGAAuthzBuilder); ok && builder.opts.PropertyName != options.Extension {
+		b := *builder
+		b.opts.PropertyName = options.Extension
+		options.Builder = &b
+	}
+
 	return func(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
 		c := middleware.GetFiberContext(ctx)
 
-		if options.Next != nil && options.Next(ctx, input) {
-			return nil
+		err := oasAuthenticate(ctx, c, input, options)
+		if c != nil {
+			oas.SetErrorStatus(c, err)
 		}
 
-		// nolint:contextcheck
-		user, relation, object, err := options.Builder.BuildWithContext(c.UserContext(), input)
-		if err != nil {
-			return err
-		}
+		return err
+	}
+}
 
-		log.Debugw("OasAuthenticate", "user", user, "relation", relation, "object", object)
+func oasAuthenticate(ctx context.Context, c *fiber.Ctx, input *openapi3filter.AuthenticationInput, options OasAuthenticateOpts) error {
+	if options.FailClosed && OperationStatus(input.RequestValidationInput.Route.Operation, options.Extension) == CoverageUnannotated {
+		return ErrUnannotatedOperation
+	}
 
-		allowed, err := options.Checker.Allowed(ctx, user, relation, object)
-		if err != nil {
-			return fiber.ErrUnauthorized
-		}
+	if options.Next != nil && options.Next(ctx, input) {
+		return nil
+	}
 
-		log.Debugw("OasAuthenticate", "allowed", allowed)
+	// The user context carries the contextual data and the consistency of the route.
+	// nolint:contextcheck
+	usrCtx := WithFiberContext(c.UserContext(), c)
 
-		if !allowed {
-			return fiber.ErrForbidden
-		}
+	if builder, ok := options.Builder.(OasFGAChecksBuilder); ok {
+		return authenticateChecks(usrCtx, c, builder, options.Checker, input)
+	}
 
-		return nil
+	user, relation, object, err := options.Builder.BuildWithContext(usrCtx, input)
+	if err != nil {
+		return err
+	}
+
+	log.Debugw("OasAuthenticate", "user", user, "relation", relation, "object", object)
+
+	allowed, err := options.Checker.Allowed(usrCtx, user, relation, object)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	log.Debugw("OasAuthenticate", "allowed", allowed)
+
+	if !allowed {
+		return fiber.ErrForbidden
 	}
+
+	return nil
+}
+
+// authenticateChecks evaluates the checks and sets the result on the user context, see GetResult.
+// The failed checks are not part of the response, as they contain the ids of the user and the objects.
+func authenticateChecks(ctx context.Context, c *fiber.Ctx, builder OasFGAChecksBuilder, checker Checker, input *openapi3filter.AuthenticationInput) error {
+	checks, err := builder.BuildChecksWithContext(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	log.Debugw("OasAuthenticate", "checks", checks.Flatten())
+
+	res, err := Evaluate(ctx, checker, checks)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	log.Debugw("OasAuthenticate", "allowed", res.Allowed, "failed", res.Reason())
+
+	// nolint:contextcheck
+	c.SetUserContext(WithResult(c.UserContext(), res))
+
+	if !res.Allowed {
+		return fiber.ErrForbidden
+	}
+
+	return nil
 }
 
 // Authenticate evalutes the authentication functions in the order they are provided.
@@ -269,3 +583,147 @@ func PathParams(params map[string]string, name string, v ...string) string {
 func QueryParams(values url.Values, name string, v ...string) string {
 	return values.Get(name)
 }
+
+// BodyPointer extracts the value at the JSON pointer from the request body.
+// The body is restored, so it can be read again.
+func BodyPointer(req *http.Request, pointer string) (string, error) {
+	if req.Body == nil {
+		return "", nil
+	}
+
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		return "", err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(b))
+
+	if len(b) == 0 {
+		return "", nil
+	}
+
+	// Numbers are kept as is, so large ids do not lose precision.
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil || dec.Decode(&struct{}{}) != io.EOF {
+		return "", fiber.NewError(fiber.StatusBadRequest, "request body is not valid JSON")
+	}
+
+	v, ok := jsonPointer(doc, pointer)
+	if !ok {
+		return "", nil
+	}
+
+	return stringValue(v), nil
+}
+
+// Claim extracts the claim from the OIDC token in the context.
+func Claim(ctx context.Context, name string) string {
+	claims, ok := oidc.GetJWTFromContext(ctx)
+	if !ok || claims == nil {
+		return ""
+	}
+
+	v, ok := claims.Claims[name]
+	if !ok {
+		return ""
+	}
+
+	return stringValue(v)
+}
+
+// ClaimValues extracts the values of a string or array claim from the OIDC token in the context.
+func ClaimValues(ctx context.Context, name string) []string {
+	claims, ok := oidc.GetJWTFromContext(ctx)
+	if !ok || claims == nil {
+		return nil
+	}
+
+	switch v := claims.Claims[name].(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		ss := make([]string, 0, len(v))
+		for _, e := range v {
+			if s := stringValue(e); s != "" {
+				ss = append(ss, s)
+			}
+		}
+
+		return ss
+	case []string:
+		return v
+	default:
+		if s := stringValue(v); s != "" {
+			return []string{s}
+		}
+
+		return nil
+	}
+}
+
+// ClientIP returns the IP address of the client.
+// The fiber context is preferred, so the proxy header settings of the app are used.
+func ClientIP(ctx context.Context, req *http.Request) string {
+	if c, ok := FiberContext(ctx); ok {
+		return c.IP()
+	}
+
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		return host
+	}
+
+	return req.RemoteAddr
+}
+
+func jsonPointer(doc interface{}, pointer string) (interface{}, bool) {
+	if pointer == "" {
+		return doc, true
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+		switch v := doc.(type) {
+		case map[string]interface{}:
+			next, ok := v[token]
+			if !ok {
+				return nil, false
+			}
+
+			doc = next
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+
+			doc = v[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return doc, true
+}
+
+func stringValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,100 @@
+package openfga_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/stretchr/testify/require"
+	"github.com/zeiss/fiber-authz/openfga"
+)
+
+func TestComponentValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		component openfga.OasFGAAuthzOptionComponent
+		value     string
+		err       bool
+	}{
+		{name: "path", component: openfga.OasFGAAuthzOptionComponent{In: "path", Name: "teamId"}, value: "zeiss"},
+		{name: "query", component: openfga.OasFGAAuthzOptionComponent{In: "query", Name: "id"}, value: "1"},
+		{name: "header", component: openfga.OasFGAAuthzOptionComponent{In: "header", Name: "X-Tenant"}, value: "tenant"},
+		{name: "cookie", component: openfga.OasFGAAuthzOptionComponent{In: "cookie", Name: "session"}, value: "abc"},
+		{name: "body", component: openfga.OasFGAAuthzOptionComponent{In: "body", Name: "/workload/ids/1"}, value: "42"},
+		{name: "value", component: openfga.OasFGAAuthzOptionComponent{In: "value", Value: "system"}, value: "system"},
+		{name: "default", component: openfga.OasFGAAuthzOptionComponent{In: "header", Name: "X-Missing", Default: "fallback"}, value: "fallback"},
+		{name: "large number", component: openfga.OasFGAAuthzOptionComponent{In: "body", Name: "/workload/id"}, value: "12345678901234567890"},
+		{name: "decimal number", component: openfga.OasFGAAuthzOptionComponent{In: "body", Name: "/workload/version"}, value: "1.10"},
+		{name: "missing", component: openfga.OasFGAAuthzOptionComponent{In: "body", Name: "/missing"}},
+		{name: "required", component: openfga.OasFGAAuthzOptionComponent{In: "query", Name: "missing", Required: true}, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/teams/zeiss?id=1", strings.NewReader(`{"workload": {"id": 12345678901234567890, "version": 1.10, "ids": [41, 42]}}`))
+			req.Header.Set("X-Tenant", "tenant")
+			req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+
+			input := &openapi3filter.AuthenticationInput{
+				RequestValidationInput: &openapi3filter.RequestValidationInput{
+					Request:    req,
+					PathParams: map[string]string{"teamId": "zeiss"},
+				},
+			}
+
+			value, err := openfga.ComponentValue(context.Background(), input, tt.component)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.value, value)
+
+			// The body can be read again.
+			b, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.NotEmpty(t, b)
+		})
+	}
+}
+
+func TestBodyPointerInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, body := range []string{`{"id": 1`, `{"id": 1} {"id": 2}`} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+		_, err := openfga.BodyPointer(req, "/id")
+		require.Error(t, err, body)
+	}
+}
+
+func TestBuildObject(t *testing.T) {
+	t.Parallel()
+
+	input := &openapi3filter.AuthenticationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    httptest.NewRequest(http.MethodGet, "/teams/zeiss", nil),
+			PathParams: map[string]string{"teamId": "zeiss"},
+		},
+	}
+
+	opts := &openfga.OasFGAAuthzOptions{}
+	opts.Object.Namespace = "team"
+	opts.Object.Components = []openfga.OasFGAAuthzOptionComponent{{In: "path", Name: "teamId"}}
+
+	require.Equal(t, openfga.Object("team:zeiss"), openfga.BuildObject(context.Background(), input, opts))
+
+	opts.Object.Components = append(opts.Object.Components, openfga.OasFGAAuthzOptionComponent{In: "query", Name: "id", Required: true})
+
+	_, err := openfga.ResolveObject(context.Background(), input, opts)
+	require.Error(t, err)
+	require.Equal(t, openfga.NoopObject, openfga.BuildObject(context.Background(), input, opts))
+}
@@ -0,0 +1,297 @@
+package openfga
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	fgasdk "github.com/openfga/go-sdk"
+	"github.com/openfga/go-sdk/client"
+)
+
+// Consistency preferences of the checks.
+const (
+	// ConsistencyMinimizeLatency prefers cached results.
+	ConsistencyMinimizeLatency = fgasdk.CONSISTENCYPREFERENCE_MINIMIZE_LATENCY
+	// ConsistencyHigher skips the cache of the server.
+	ConsistencyHigher = fgasdk.CONSISTENCYPREFERENCE_HIGHER_CONSISTENCY
+)
+
+// ErrNoStoreModelID is returned if the resolved store of a tenant has no authorization model.
+// The client would fall back to its default model, which belongs to another store.
+var ErrNoStoreModelID = errors.New("openfga: resolved store has no authorization model id")
+
+// Store is the store and authorization model of a tenant.
+type Store struct {
+	// ID is the id of the store.
+	ID string
+	// ModelID is the id of the authorization model, it is required.
+	ModelID string
+}
+
+// StoreResolver resolves the store of the tenant of the request.
+// The default store of the client is used if it returns false.
+// The resolved store must have a model id, see ErrNoStoreModelID.
+type StoreResolver func(ctx context.Context) (Store, bool)
+
+// ClientOpts are the options of the OpenFGA checks.
+type ClientOpts struct {
+	// ModelID pins the checks to an authorization model.
+	ModelID string
+	// Consistency is the default consistency preference.
+	Consistency fgasdk.ConsistencyPreference
+	// StoreResolver overrides the store per tenant.
+	StoreResolver StoreResolver
+	// MaxTuplesPerWrite is the maximum number of tuples of a write transaction.
+	MaxTuplesPerWrite int
+	// StrictWrites fails writes of existing and deletes of missing tuples.
+	StrictWrites bool
+}
+
+// Configure sets the configuration for the options.
+func (o *ClientOpts) Configure(opts ...ClientOpt) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// ClientOpt ...
+type ClientOpt func(*ClientOpts)
+
+// DefaultClientOpts returns the default options.
+func DefaultClientOpts() ClientOpts {
+	return ClientOpts{
+		MaxTuplesPerWrite: DefaultMaxTuplesPerWrite,
+	}
+}
+
+// WithModelID pins the checks to the authorization model.
+func WithModelID(id string) ClientOpt {
+	return func(o *ClientOpts) {
+		o.ModelID = id
+	}
+}
+
+// WithConsistency sets the default consistency preference.
+func WithConsistency(consistency fgasdk.ConsistencyPreference) ClientOpt {
+	return func(o *ClientOpts) {
+		o.Consistency = consistency
+	}
+}
+
+// WithStoreResolver sets the resolver of the store per tenant.
+func WithStoreResolver(resolver StoreResolver) ClientOpt {
+	return func(o *ClientOpts) {
+		o.StoreResolver = resolver
+	}
+}
+
+// WithMaxTuplesPerWrite sets the maximum number of tuples of a write transaction.
+func WithMaxTuplesPerWrite(n int) ClientOpt {
+	return func(o *ClientOpts) {
+		o.MaxTuplesPerWrite = n
+	}
+}
+
+// WithStrictWrites fails writes of existing and deletes of missing tuples.
+// By default writes are idempotent.
+func WithStrictWrites() ClientOpt {
+	return func(o *ClientOpts) {
+		o.StrictWrites = true
+	}
+}
+
+// CheckOptions are the request options of the checks.
+// The authorization model can be switched atomically while checks are running.
+type CheckOptions struct {
+	opts    ClientOpts
+	modelID atomic.Pointer[string]
+}
+
+// NewCheckOptions returns new check options.
+func NewCheckOptions(opts ...ClientOpt) *CheckOptions {
+	options := DefaultClientOpts()
+	options.Configure(opts...)
+
+	o := &CheckOptions{opts: options}
+	o.SetModelID(options.ModelID)
+
+	return o
+}
+
+// ModelID returns the id of the pinned authorization model.
+func (o *CheckOptions) ModelID() string {
+	return *o.modelID.Load()
+}
+
+// SetModelID pins the checks to the authorization model.
+// An empty id uses the model of the client.
+func (o *CheckOptions) SetModelID(id string) {
+	o.modelID.Store(&id)
+}
+
+// ReloadModelID pins the checks to the latest authorization model of the store.
+func (o *CheckOptions) ReloadModelID(ctx context.Context, c *client.OpenFgaClient) error {
+	res, err := c.ReadLatestAuthorizationModel(ctx).Execute()
+	if err != nil {
+		return err
+	}
+
+	model := res.GetAuthorizationModel()
+	o.SetModelID(model.GetId())
+
+	return nil
+}
+
+// ReloadModelIDEvery reloads the latest authorization model in the interval until the context is done.
+// Failed reloads are logged and keep the current model.
+func (o *CheckOptions) ReloadModelIDEvery(ctx context.Context, c *client.OpenFgaClient, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := o.ReloadModelID(ctx, c); err != nil {
+				log.Errorw("ReloadModelIDEvery", "error", err)
+			}
+		}
+	}
+}
+
+// CheckRequestOptions returns the request options of a check.
+func (o *CheckOptions) CheckRequestOptions(ctx context.Context) (client.ClientCheckOptions, error) {
+	store, model, consistency, err := o.resolve(ctx)
+	if err != nil {
+		return client.ClientCheckOptions{}, err
+	}
+
+	return client.ClientCheckOptions{StoreId: store, AuthorizationModelId: model, Consistency: consistency}, nil
+}
+
+// BatchCheckRequestOptions returns the request options of a batch check.
+func (o *CheckOptions) BatchCheckRequestOptions(ctx context.Context) (client.BatchCheckOptions, error) {
+	store, model, consistency, err := o.resolve(ctx)
+	if err != nil {
+		return client.BatchCheckOptions{}, err
+	}
+
+	return client.BatchCheckOptions{StoreId: store, AuthorizationModelId: model, Consistency: consistency}, nil
+}
+
+// ListObjectsRequestOptions returns the request options of a list objects request.
+func (o *CheckOptions) ListObjectsRequestOptions(ctx context.Context) (client.ClientListObjectsOptions, error) {
+	store, model, consistency, err := o.resolve(ctx)
+	if err != nil {
+		return client.ClientListObjectsOptions{}, err
+	}
+
+	return client.ClientListObjectsOptions{StoreId: store, AuthorizationModelId: model, Consistency: consistency}, nil
+}
+
+// MaxTuplesPerWrite returns the maximum number of tuples of a write transaction.
+func (o *CheckOptions) MaxTuplesPerWrite() int {
+	return o.opts.MaxTuplesPerWrite
+}
+
+// WriteRequestOptions returns the request options of a write request.
+// Each request is a single transaction.
+func (o *CheckOptions) WriteRequestOptions(ctx context.Context) (client.ClientWriteOptions, error) {
+	store, model, _, err := o.resolve(ctx)
+	if err != nil {
+		return client.ClientWriteOptions{}, err
+	}
+
+	conflict := client.ClientWriteConflictOptions{
+		OnDuplicateWrites: client.CLIENT_WRITE_REQUEST_ON_DUPLICATE_WRITES_IGNORE,
+		OnMissingDeletes:  client.CLIENT_WRITE_REQUEST_ON_MISSING_DELETES_IGNORE,
+	}
+
+	if o.opts.StrictWrites {
+		conflict = client.ClientWriteConflictOptions{
+			OnDuplicateWrites: client.CLIENT_WRITE_REQUEST_ON_DUPLICATE_WRITES_ERROR,
+			OnMissingDeletes:  client.CLIENT_WRITE_REQUEST_ON_MISSING_DELETES_ERROR,
+		}
+	}
+
+	return client.ClientWriteOptions{StoreId: store, AuthorizationModelId: model, Conflict: conflict}, nil
+}
+
+// StoreScope returns the store and authorization model of the checks of the request,
+// e.g. to keep the cached checks of tenants and models apart.
+func (o *CheckOptions) StoreScope(ctx context.Context) (string, error) {
+	store, model, _, err := o.resolve(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var scope string
+	if store != nil {
+		scope = *store
+	}
+
+	scope += "#"
+	if model != nil {
+		scope += *model
+	}
+
+	return scope, nil
+}
+
+func (o *CheckOptions) resolve(ctx context.Context) (*string, *string, *fgasdk.ConsistencyPreference, error) {
+	var store, model *string
+
+	if id := o.ModelID(); id != "" {
+		model = &id
+	}
+
+	if o.opts.StoreResolver != nil {
+		if s, ok := o.opts.StoreResolver(ctx); ok {
+			if s.ModelID == "" {
+				return nil, nil, nil, ErrNoStoreModelID
+			}
+
+			store, model = &s.ID, &s.ModelID
+		}
+	}
+
+	consistency := o.opts.Consistency
+	if c, ok := GetConsistency(ctx); ok {
+		consistency = c
+	}
+
+	if consistency == "" {
+		return store, model, nil, nil
+	}
+
+	return store, model, &consistency, nil
+}
+
+type consistencyKey struct{}
+
+// WithConsistencyContext returns a new context with the consistency preference of the checks.
+func WithConsistencyContext(ctx context.Context, consistency fgasdk.ConsistencyPreference) context.Context {
+	return context.WithValue(ctx, consistencyKey{}, consistency)
+}
+
+// GetConsistency returns the consistency preference from the context.
+func GetConsistency(ctx context.Context) (fgasdk.ConsistencyPreference, bool) {
+	consistency, ok := ctx.Value(consistencyKey{}).(fgasdk.ConsistencyPreference)
+
+	return consistency, ok
+}
+
+// Consistency is a middleware that sets the consistency preference of the checks of a route.
+func Consistency(consistency fgasdk.ConsistencyPreference) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// nolint: contextcheck
+		c.SetUserContext(WithConsistencyContext(c.UserContext(), consistency))
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,216 @@
+package openfga_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/openfga/go-sdk/client"
+	"github.com/stretchr/testify/require"
+	"github.com/zeiss/fiber-authz/openfga"
+)
+
+func TestCheckOptions(t *testing.T) {
+	t.Parallel()
+
+	tenants := func(ctx context.Context) (openfga.Store, bool) {
+		tenant, ok := ctx.Value("tenant").(string)
+		if !ok {
+			return openfga.Store{}, false
+		}
+
+		return openfga.Store{ID: tenant, ModelID: "model-" + tenant}, true
+	}
+
+	opts := openfga.NewCheckOptions(
+		openfga.WithModelID("model-1"),
+		openfga.WithConsistency(openfga.ConsistencyMinimizeLatency),
+		openfga.WithStoreResolver(tenants),
+	)
+
+	req, err := opts.CheckRequestOptions(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, req.StoreId)
+	require.Equal(t, "model-1", *req.AuthorizationModelId)
+	require.Equal(t, openfga.ConsistencyMinimizeLatency, *req.Consistency)
+
+	opts.SetModelID("model-2")
+	require.Equal(t, "model-2", opts.ModelID())
+
+	ctx := openfga.WithConsistencyContext(context.WithValue(context.Background(), "tenant", "store-a"), openfga.ConsistencyHigher) //nolint:staticcheck
+	batch, err := opts.BatchCheckRequestOptions(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "store-a", *batch.StoreId)
+	require.Equal(t, "model-store-a", *batch.AuthorizationModelId)
+	require.Equal(t, openfga.ConsistencyHigher, *batch.Consistency)
+
+	req, err = openfga.NewCheckOptions().CheckRequestOptions(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, req.AuthorizationModelId)
+	require.Nil(t, req.Consistency)
+}
+
+func TestClientStoreModelID(t *testing.T) {
+	t.Parallel()
+
+	const (
+		defaultStore = "01ARZ3NDEKTSV4RRFFQ69G5FAV"
+		defaultModel = "01ARZ3NDEKTSV4RRFFQ69G5FAW"
+		tenantStore  = "01ARZ3NDEKTSV4RRFFQ69G5FAX"
+		tenantModel  = "01ARZ3NDEKTSV4RRFFQ69G5FAY"
+	)
+
+	var model atomic.Value
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			AuthorizationModelID string `json:"authorization_model_id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		model.Store(body.AuthorizationModelID)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"allowed":true}`))
+	}))
+	defer srv.Close()
+
+	fgaClient, err := client.NewSdkClient(&client.ClientConfiguration{
+		ApiUrl:               srv.URL,
+		StoreId:              defaultStore,
+		AuthorizationModelId: defaultModel,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		store openfga.Store
+		model string
+		err   error
+	}{
+		{
+			name:  "tenant model",
+			store: openfga.Store{ID: tenantStore, ModelID: tenantModel},
+			model: tenantModel,
+		},
+		{
+			name:  "tenant without model",
+			store: openfga.Store{ID: tenantStore},
+			err:   openfga.ErrNoStoreModelID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := openfga.NewClient(fgaClient, openfga.WithStoreResolver(func(context.Context) (openfga.Store, bool) {
+				return tt.store, true
+			}))
+
+			model.Store("")
+
+			_, err := c.Allowed(context.Background(), "user:alice", "reader", "document:a")
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.model, model.Load())
+		})
+	}
+}
+
+func TestCacheStoreScope(t *testing.T) {
+	t.Parallel()
+
+	const (
+		defaultStore = "01ARZ3NDEKTSV4RRFFQ69G5FAV"
+		storeA       = "01ARZ3NDEKTSV4RRFFQ69G5FAX"
+		storeB       = "01ARZ3NDEKTSV4RRFFQ69G5FAY"
+		model1       = "01ARZ3NDEKTSV4RRFFQ69G5FAZ"
+		model2       = "01ARZ3NDEKTSV4RRFFQ69G5FB0"
+	)
+
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		var body struct {
+			AuthorizationModelID string `json:"authorization_model_id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		allowed := r.URL.Path == "/stores/"+storeA+"/check" || body.AuthorizationModelID == model2
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]bool{"allowed": allowed})
+	}))
+	defer srv.Close()
+
+	fgaClient, err := client.NewSdkClient(&client.ClientConfiguration{
+		ApiUrl:  srv.URL,
+		StoreId: defaultStore,
+	})
+	require.NoError(t, err)
+
+	c := openfga.NewClient(fgaClient, openfga.WithModelID(model1), openfga.WithStoreResolver(func(ctx context.Context) (openfga.Store, bool) {
+		store, ok := ctx.Value("tenant").(string)
+
+		return openfga.Store{ID: store, ModelID: model1}, ok
+	}))
+	cache := openfga.NewCache(c)
+
+	tenantA := context.WithValue(context.Background(), "tenant", storeA) //nolint:staticcheck
+	tenantB := context.WithValue(context.Background(), "tenant", storeB) //nolint:staticcheck
+
+	allowed, err := cache.Allowed(tenantA, "user:alice", "viewer", "doc:1")
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	allowed, err = cache.Allowed(tenantB, "user:alice", "viewer", "doc:1")
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	allowed, err = cache.Allowed(tenantA, "user:alice", "viewer", "doc:1")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.Equal(t, int32(2), calls.Load())
+
+	allowed, err = cache.Allowed(context.Background(), "user:alice", "viewer", "doc:1")
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	c.SetModelID(model2)
+
+	allowed, err = cache.Allowed(context.Background(), "user:alice", "viewer", "doc:1")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.Equal(t, int32(4), calls.Load())
+}
+
+func TestClientBatchAllowedItemError(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"result":{"0":{"allowed":true},"1":{"allowed":false,"error":{"message":"type not found"}}}}`))
+	}))
+	defer srv.Close()
+
+	fgaClient, err := client.NewSdkClient(&client.ClientConfiguration{ApiUrl: srv.URL, StoreId: "01ARZ3NDEKTSV4RRFFQ69G5FAV"})
+	require.NoError(t, err)
+
+	checks := []openfga.Check{
+		{User: "user:alice", Relation: "viewer", Object: "doc:1"},
+		{User: "user:alice", Relation: "viewer", Object: "unknown:1"},
+	}
+
+	allowed, err := openfga.NewClient(fgaClient).BatchAllowed(context.Background(), checks)
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, false}, allowed)
+
+	res, err := openfga.Evaluate(context.Background(), openfga.NewClient(fgaClient), openfga.Checks{
+		Combinator: openfga.CombinatorAny,
+		Checks:     []openfga.Checks{{Check: &checks[0]}, {Check: &checks[1]}},
+	})
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+}
@@ -0,0 +1,259 @@
+package openfga
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/openfga/go-sdk/client"
+)
+
+// DefaultMaxTuplesPerWrite is the maximum number of tuples of a write request of the OpenFGA server.
+const DefaultMaxTuplesPerWrite = 100
+
+// TupleWriter is an interface for writing and deleting tuples.
+type TupleWriter interface {
+	// Write writes the tuples.
+	Write(ctx context.Context, tuples ...Tuple) error
+	// Delete deletes the tuples.
+	Delete(ctx context.Context, tuples ...Tuple) error
+}
+
+var _ TupleWriter = (*ClientImpl)(nil)
+
+// Invalidator removes the cached checks that depend on the tuples, e.g. Cache.
+type Invalidator interface {
+	// Invalidate removes the cached checks of the users and objects of the tuples.
+	Invalidate(tuples ...Tuple)
+}
+
+// Flusher removes all cached checks, e.g. Cache.
+type Flusher interface {
+	// Flush removes all cached checks.
+	Flush()
+}
+
+// NewTuple returns a new tuple.
+func NewTuple(user User, relation Relation, object Object) Tuple {
+	return Tuple{User: user, Relation: relation, Object: object}
+}
+
+// String returns the string representation of the tuple.
+func (t Tuple) String() string {
+	return fmt.Sprintf("%s %s %s", t.User, t.Relation, t.Object)
+}
+
+// Validate returns an error if the tuple is incomplete.
+func (t Tuple) Validate() error {
+	if !strings.Contains(EntityString(t.User), DefaultNamespaceSeparator) {
+		return fmt.Errorf("invalid user %q", t.User)
+	}
+
+	if t.Relation == NoopRelation {
+		return fmt.Errorf("empty relation of %q", t.String())
+	}
+
+	if !strings.Contains(EntityString(t.Object), DefaultNamespaceSeparator) {
+		return fmt.Errorf("invalid object %q", t.Object)
+	}
+
+	return nil
+}
+
+// Write writes the tuples.
+// The tuples are written in transactions of at most MaxTuplesPerWrite tuples,
+// so a failed write can leave the tuples of earlier transactions written.
+// Existing tuples are ignored unless WithStrictWrites is set.
+func (c *ClientImpl) Write(ctx context.Context, tuples ...Tuple) error {
+	opts, err := c.opts.WriteRequestOptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, chunk := range chunks(tuples, c.opts.MaxTuplesPerWrite()) {
+		keys := make([]client.ClientTupleKey, len(chunk))
+		for i, t := range chunk {
+			keys[i] = client.ClientTupleKey{User: EntityString(t.User), Relation: EntityString(t.Relation), Object: EntityString(t.Object)}
+		}
+
+		_, err := c.client.Write(ctx).Body(client.ClientWriteRequest{Writes: keys}).Options(opts).Execute()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete deletes the tuples.
+// The tuples are deleted in transactions of at most MaxTuplesPerWrite tuples.
+// Missing tuples are ignored unless WithStrictWrites is set.
+func (c *ClientImpl) Delete(ctx context.Context, tuples ...Tuple) error {
+	opts, err := c.opts.WriteRequestOptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, chunk := range chunks(tuples, c.opts.MaxTuplesPerWrite()) {
+		keys := make([]client.ClientTupleKeyWithoutCondition, len(chunk))
+		for i, t := range chunk {
+			keys[i] = client.ClientTupleKeyWithoutCondition{User: EntityString(t.User), Relation: EntityString(t.Relation), Object: EntityString(t.Object)}
+		}
+
+		_, err := c.client.Write(ctx).Body(client.ClientWriteRequest{Deletes: keys}).Options(opts).Execute()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func chunks(tuples []Tuple, size int) [][]Tuple {
+	if size <= 0 {
+		size = DefaultMaxTuplesPerWrite
+	}
+
+	cc := [][]Tuple{}
+
+	for start := 0; start < len(tuples); start += size {
+		cc = append(cc, tuples[start:min(start+size, len(tuples))])
+	}
+
+	return cc
+}
+
+// TuplesRequest is the request body of the grant and revoke handlers.
+type TuplesRequest struct {
+	// Tuples are the tuples to grant or revoke.
+	Tuples []Tuple `json:"tuples"`
+}
+
+// TuplesOpts are the options of the grant and revoke handlers.
+type TuplesOpts struct {
+	// UserResolver resolves the user of the request.
+	UserResolver ListUserResolver
+	// Invalidators remove the cached checks that depend on the tuples.
+	Invalidators []Invalidator
+}
+
+// Configure sets the configuration for the grant and revoke handlers.
+func (o *TuplesOpts) Configure(opts ...TuplesOpt) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// TuplesOpt ...
+type TuplesOpt func(*TuplesOpts)
+
+// DefaultTuplesOpts returns the default options.
+func DefaultTuplesOpts() TuplesOpts {
+	return TuplesOpts{
+		UserResolver: DefaultListUserResolver,
+	}
+}
+
+// WithTuplesUserResolver sets the user resolver of the grant and revoke handlers.
+func WithTuplesUserResolver(resolver ListUserResolver) TuplesOpt {
+	return func(o *TuplesOpts) {
+		o.UserResolver = resolver
+	}
+}
+
+// WithInvalidators sets the invalidators that are called after the tuples have been
+// written or deleted, e.g. the caches of the checks.
+func WithInvalidators(invalidators ...Invalidator) TuplesOpt {
+	return func(o *TuplesOpts) {
+		o.Invalidators = append(o.Invalidators, invalidators...)
+	}
+}
+
+// NewGrantHandler returns a handler that writes the tuples of the request.
+// Only users with the relation on the object are allowed to grant, e.g. admin on system:tuples.
+// The cached checks of the users and objects of the tuples are invalidated,
+// denied checks that depend on the tuples through other objects expire with their TTL.
+func NewGrantHandler(writer TupleWriter, checker Checker, relation Relation, object Object, opts ...TuplesOpt) fiber.Handler {
+	options := DefaultTuplesOpts()
+	options.Configure(opts...)
+
+	return newTuplesHandler(checker, relation, object, options, writer.Write, func(tuples []Tuple) {
+		for _, i := range options.Invalidators {
+			i.Invalidate(tuples...)
+		}
+	})
+}
+
+// NewRevokeHandler returns a handler that deletes the tuples of the request.
+// Only users with the relation on the object are allowed to revoke, e.g. admin on system:tuples.
+// Invalidators that implement Flusher are flushed, as revoked tuples can allow checks
+// of other users and objects, e.g. the members of a team or the documents of a folder.
+func NewRevokeHandler(writer TupleWriter, checker Checker, relation Relation, object Object, opts ...TuplesOpt) fiber.Handler {
+	options := DefaultTuplesOpts()
+	options.Configure(opts...)
+
+	return newTuplesHandler(checker, relation, object, options, writer.Delete, func(tuples []Tuple) {
+		for _, i := range options.Invalidators {
+			if f, ok := i.(Flusher); ok {
+				f.Flush()
+				continue
+			}
+
+			i.Invalidate(tuples...)
+		}
+	})
+}
+
+// newTuplesHandler returns a handler that checks the user of the request and calls fn with the tuples.
+// The tuples are invalidated even if fn fails, as earlier transactions may have been committed.
+func newTuplesHandler(
+	checker Checker,
+	relation Relation,
+	object Object,
+	opts TuplesOpts,
+	fn func(ctx context.Context, tuples ...Tuple) error,
+	invalidate func(tuples []Tuple),
+) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, err := opts.UserResolver(c)
+		if err != nil {
+			return err
+		}
+
+		// nolint:contextcheck
+		allowed, err := checker.Allowed(c.UserContext(), user, relation, object)
+		if err != nil {
+			return err
+		}
+
+		if !allowed {
+			return fiber.ErrForbidden
+		}
+
+		var payload TuplesRequest
+		if err := c.BodyParser(&payload); err != nil {
+			return fiber.ErrBadRequest
+		}
+
+		if len(payload.Tuples) == 0 {
+			return fiber.NewError(fiber.StatusBadRequest, "no tuples")
+		}
+
+		for _, t := range payload.Tuples {
+			if err := t.Validate(); err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, err.Error())
+			}
+		}
+
+		// nolint:contextcheck
+		err = fn(c.UserContext(), payload.Tuples...)
+		invalidate(payload.Tuples)
+
+		if err != nil {
+			return err
+		}
+
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
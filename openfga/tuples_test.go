@@ -0,0 +1,261 @@
+package openfga_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/openfga/go-sdk/client"
+	"github.com/stretchr/testify/require"
+	"github.com/zeiss/fiber-authz/openfga"
+)
+
+func TestClientWrite(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		writes []int
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Writes struct {
+				TupleKeys []interface{} `json:"tuple_keys"`
+			} `json:"writes"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		mu.Lock()
+		writes = append(writes, len(body.Writes.TupleKeys))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	fgaClient, err := client.NewSdkClient(&client.ClientConfiguration{
+		ApiUrl:  srv.URL,
+		StoreId: "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+	})
+	require.NoError(t, err)
+
+	c := openfga.NewClient(fgaClient, openfga.WithMaxTuplesPerWrite(2))
+
+	tuples := []openfga.Tuple{
+		openfga.NewTuple("user:alice", "editor", "team:zeiss"),
+		openfga.NewTuple("user:bob", "editor", "team:zeiss"),
+		openfga.NewTuple("user:carol", "editor", "team:zeiss"),
+	}
+
+	require.NoError(t, c.Write(context.Background(), tuples...))
+	require.Equal(t, []int{2, 1}, writes)
+}
+
+type tuplesRecorder struct {
+	written []openfga.Tuple
+	deleted []openfga.Tuple
+	err     error
+}
+
+func (r *tuplesRecorder) Write(_ context.Context, tuples ...openfga.Tuple) error {
+	r.written = append(r.written, tuples...)
+	return r.err
+}
+
+func (r *tuplesRecorder) Delete(_ context.Context, tuples ...openfga.Tuple) error {
+	r.deleted = append(r.deleted, tuples...)
+	return r.err
+}
+
+func headerUserResolver(c *fiber.Ctx) (openfga.User, error) {
+	user := c.Get("X-User")
+	if user == "" {
+		return openfga.NoopUser, openfga.ErrNoIdentity
+	}
+
+	return openfga.NewUser(openfga.Namespace("user"), openfga.String(user)), nil
+}
+
+func newTuplesApp(rec *tuplesRecorder, invalidators ...openfga.Invalidator) *fiber.App {
+	admins := checkerFunc(func(_ context.Context, user openfga.User, relation openfga.Relation, object openfga.Object) (bool, error) {
+		return user == "user:admin" && relation == "admin" && object == "system:tuples", nil
+	})
+
+	opts := []openfga.TuplesOpt{openfga.WithTuplesUserResolver(headerUserResolver), openfga.WithInvalidators(invalidators...)}
+
+	app := fiber.New()
+	app.Post("/grant", openfga.NewGrantHandler(rec, admins, "admin", "system:tuples", opts...))
+	app.Post("/revoke", openfga.NewRevokeHandler(rec, admins, "admin", "system:tuples", opts...))
+
+	return app
+}
+
+func TestGrantRevokeHandler(t *testing.T) {
+	t.Parallel()
+
+	rec := &tuplesRecorder{}
+	inv := &invalidatorRecorder{}
+	app := newTuplesApp(rec, inv)
+
+	tests := []struct {
+		name   string
+		path   string
+		user   string
+		body   string
+		status int
+	}{
+		{
+			name:   "grant",
+			path:   "/grant",
+			user:   "admin",
+			body:   `{"tuples":[{"user":"user:alice","relation":"editor","object":"team:zeiss"}]}`,
+			status: fiber.StatusNoContent,
+		},
+		{
+			name:   "revoke",
+			path:   "/revoke",
+			user:   "admin",
+			body:   `{"tuples":[{"user":"user:bob","relation":"editor","object":"team:zeiss"}]}`,
+			status: fiber.StatusNoContent,
+		},
+		{
+			name:   "no identity",
+			path:   "/grant",
+			body:   `{"tuples":[{"user":"user:carol","relation":"editor","object":"team:zeiss"}]}`,
+			status: fiber.StatusUnauthorized,
+		},
+		{
+			name:   "no admin",
+			path:   "/revoke",
+			user:   "carol",
+			body:   `{"tuples":[{"user":"user:alice","relation":"editor","object":"team:zeiss"}]}`,
+			status: fiber.StatusForbidden,
+		},
+		{
+			name:   "invalid tuple",
+			path:   "/grant",
+			user:   "admin",
+			body:   `{"tuples":[{"user":"alice","relation":"editor","object":"team:zeiss"}]}`,
+			status: fiber.StatusBadRequest,
+		},
+		{
+			name:   "no tuples",
+			path:   "/grant",
+			user:   "admin",
+			body:   `{}`,
+			status: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(fiber.MethodPost, tt.path, strings.NewReader(tt.body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set("X-User", tt.user)
+
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, tt.status, res.StatusCode, tt.name)
+	}
+
+	require.Equal(t, []openfga.Tuple{openfga.NewTuple("user:alice", "editor", "team:zeiss")}, rec.written)
+	require.Equal(t, []openfga.Tuple{openfga.NewTuple("user:bob", "editor", "team:zeiss")}, rec.deleted)
+	require.Equal(t, append(rec.written, rec.deleted...), inv.invalidated)
+}
+
+func TestGrantRevokeHandlerInvalidate(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	checker := checkerFunc(func(context.Context, openfga.User, openfga.Relation, openfga.Object) (bool, error) {
+		calls++
+		return true, nil
+	})
+
+	tests := []struct {
+		name  string
+		path  string
+		err   error
+		calls int
+	}{
+		{
+			name:  "failed grant invalidates the tuples",
+			path:  "/grant",
+			err:   fiber.ErrInternalServerError,
+			calls: 1,
+		},
+		{
+			name:  "revoke flushes the cache",
+			path:  "/revoke",
+			calls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		calls = 0
+		cache := openfga.NewCache(checker)
+		rec := &tuplesRecorder{err: tt.err}
+		inv := &invalidatorRecorder{}
+		app := newTuplesApp(rec, cache, inv)
+
+		// the cached check depends on the tuple of the request only through the folder.
+		_, err := cache.Allowed(context.Background(), "user:alice", "viewer", "doc:1")
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(fiber.MethodPost, tt.path, strings.NewReader(`{"tuples":[{"user":"team:x#member","relation":"viewer","object":"folder:a"}]}`))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set("X-User", "admin")
+
+		_, err = app.Test(req)
+		require.NoError(t, err)
+		require.Len(t, inv.invalidated, 1, tt.name)
+
+		_, err = cache.Allowed(context.Background(), "user:alice", "viewer", "doc:1")
+		require.NoError(t, err)
+
+		require.Equal(t, tt.calls, calls, tt.name)
+	}
+}
+
+type invalidatorRecorder struct {
+	invalidated []openfga.Tuple
+}
+
+func (r *invalidatorRecorder) Invalidate(tuples ...openfga.Tuple) {
+	r.invalidated = append(r.invalidated, tuples...)
+}
+
+type checkerFunc func(ctx context.Context, user openfga.User, relation openfga.Relation, object openfga.Object) (bool, error)
+
+func (f checkerFunc) Allowed(ctx context.Context, user openfga.User, relation openfga.Relation, object openfga.Object) (bool, error) {
+	return f(ctx, user, relation, object)
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	checker := checkerFunc(func(context.Context, openfga.User, openfga.Relation, openfga.Object) (bool, error) {
+		calls++
+		return true, nil
+	})
+
+	c := openfga.NewCache(checker)
+
+	for range 2 {
+		_, err := c.Allowed(context.Background(), "user:alice", "editor", "team:zeiss")
+		require.NoError(t, err)
+	}
+
+	c.Invalidate(openfga.NewTuple("user:alice", "editor", "team:zeiss"))
+
+	_, err := c.Allowed(context.Background(), "user:alice", "editor", "team:zeiss")
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+}
@@ -0,0 +1,118 @@
+package openfga
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gofiber/fiber/v2"
+	"github.com/zeiss/fiber-authz/oas/oidc"
+	goth "github.com/zeiss/fiber-goth"
+)
+
+// Authentication types of the user.
+const (
+	// AuthTypeOIDC is the subject or a claim of the OIDC token.
+	AuthTypeOIDC = "oidc"
+	// AuthTypeGoth is the user of the goth session.
+	AuthTypeGoth = "goth"
+	// AuthTypeAPIKey is the principal of an API key.
+	AuthTypeAPIKey = "apikey"
+)
+
+// ErrNoIdentity is returned when the request has no identity.
+var ErrNoIdentity = fiber.NewError(fiber.StatusUnauthorized, "no identity")
+
+// UserProvider returns the id of the user of the request.
+// It returns ErrNoIdentity if the request has no identity.
+type UserProvider func(ctx context.Context, input *openapi3filter.AuthenticationInput, opt OasFGAAuthzOption) (string, error)
+
+// UserProviders are the user providers by authentication type.
+type UserProviders map[string]UserProvider
+
+// DefaultUserProviders returns the default user providers.
+// The OIDC provider is used if no authentication type is set.
+func DefaultUserProviders() UserProviders {
+	return UserProviders{
+		"":           OidcUserProvider,
+		AuthTypeOIDC: OidcUserProvider,
+		AuthTypeGoth: GothUserProvider,
+	}
+}
+
+// OidcUserProvider returns the subject of the OIDC token,
+// or the claim that is set in the user option.
+func OidcUserProvider(ctx context.Context, _ *openapi3filter.AuthenticationInput, opt OasFGAAuthzOption) (string, error) {
+	claims, ok := oidc.GetJWTFromContext(ctx)
+	if !ok || claims == nil {
+		return "", ErrNoIdentity
+	}
+
+	id := claims.Subject
+	if opt.Claim != "" {
+		id = Claim(ctx, opt.Claim)
+	}
+
+	if id == "" {
+		return "", ErrNoIdentity
+	}
+
+	return id, nil
+}
+
+// GothUserProvider returns the email of the user of the goth session.
+func GothUserProvider(ctx context.Context, _ *openapi3filter.AuthenticationInput, _ OasFGAAuthzOption) (string, error) {
+	c, ok := FiberContext(ctx)
+	if !ok {
+		return "", ErrNoIdentity
+	}
+
+	session, err := goth.SessionFromContext(c)
+	if err != nil || session.User.Email == "" {
+		return "", ErrNoIdentity
+	}
+
+	return session.User.Email, nil
+}
+
+// BuildUser builds the user with the default user providers.
+// It returns NoopUser if the request has no identity, see BuildUserWithProviders.
+func BuildUser(ctx context.Context, input *openapi3filter.AuthenticationInput, opts *OasFGAAuthzOptions) User {
+	user, err := BuildUserWithProviders(ctx, input, opts, DefaultUserProviders())
+	if err != nil {
+		return NoopUser
+	}
+
+	return user
+}
+
+// BuildUserWithProviders builds the user with the provider of the authentication type.
+// It returns ErrNoIdentity if the request has no identity.
+func BuildUserWithProviders(ctx context.Context, input *openapi3filter.AuthenticationInput, opts *OasFGAAuthzOptions, providers UserProviders) (User, error) {
+	provider, ok := providers[opts.User.AuthType]
+	if !ok {
+		return NoopUser, fmt.Errorf("unknown auth type %q", opts.User.AuthType)
+	}
+
+	id, err := provider(ctx, input, opts.User)
+	if err != nil {
+		return NoopUser, err
+	}
+
+	return NewUser(Namespace(opts.User.Namespace), String(id)), nil
+}
+
+type fiberContextKey struct{}
+
+// WithFiberContext returns a new context with the fiber context,
+// so user providers can access the request.
+func WithFiberContext(ctx context.Context, c *fiber.Ctx) context.Context {
+	return context.WithValue(ctx, fiberContextKey{}, c)
+}
+
+// FiberContext returns the fiber context from the context.
+func FiberContext(ctx context.Context) (*fiber.Ctx, bool) {
+	c, ok := ctx.Value(fiberContextKey{}).(*fiber.Ctx)
+
+	return c, ok
+}
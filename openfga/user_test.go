@@ -0,0 +1,276 @@
+package openfga_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gofiber/fiber/v2"
+	middleware "github.com/oapi-codegen/fiber-middleware"
+	"github.com/stretchr/testify/require"
+	authz "github.com/zeiss/fiber-authz"
+	"github.com/zeiss/fiber-authz/oas"
+	"github.com/zeiss/fiber-authz/oas/oidc"
+	"github.com/zeiss/fiber-authz/openfga"
+)
+
+func TestBuildUser(t *testing.T) {
+	t.Parallel()
+
+	claims := &oas.AuthClaims{
+		Subject: "alice",
+		Claims:  map[string]interface{}{"sub": "alice", "email": "alice@example.com"},
+	}
+
+	providers := openfga.DefaultUserProviders()
+	providers["static"] = func(_ context.Context, _ *openapi3filter.AuthenticationInput, _ openfga.OasFGAAuthzOption) (string, error) {
+		return "bob", nil
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		opt  openfga.OasFGAAuthzOption
+		user openfga.User
+		err  error
+	}{
+		{
+			name: "oidc subject",
+			ctx:  oidc.WithJWT(context.Background(), claims),
+			opt:  openfga.OasFGAAuthzOption{Namespace: "user", AuthType: "oidc"},
+			user: "user:alice",
+		},
+		{
+			name: "oidc claim",
+			ctx:  oidc.WithJWT(context.Background(), claims),
+			opt:  openfga.OasFGAAuthzOption{Namespace: "user", Claim: "email"},
+			user: "user:alice@example.com",
+		},
+		{
+			name: "no oidc token",
+			ctx:  context.Background(),
+			opt:  openfga.OasFGAAuthzOption{Namespace: "user", AuthType: "oidc"},
+			err:  openfga.ErrNoIdentity,
+		},
+		{
+			name: "no goth session",
+			ctx:  context.Background(),
+			opt:  openfga.OasFGAAuthzOption{Namespace: "user", AuthType: "goth"},
+			err:  openfga.ErrNoIdentity,
+		},
+		{
+			name: "custom provider",
+			ctx:  context.Background(),
+			opt:  openfga.OasFGAAuthzOption{Namespace: "user", AuthType: "static"},
+			user: "user:bob",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := &openapi3filter.AuthenticationInput{
+				RequestValidationInput: &openapi3filter.RequestValidationInput{
+					Request: httptest.NewRequest(http.MethodGet, "/", nil),
+				},
+			}
+
+			user, err := openfga.BuildUserWithProviders(tt.ctx, input, &openfga.OasFGAAuthzOptions{User: tt.opt}, providers)
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.user, user)
+		})
+	}
+
+	_, err := openfga.BuildUserWithProviders(context.Background(), nil, &openfga.OasFGAAuthzOptions{User: openfga.OasFGAAuthzOption{AuthType: "unknown"}}, providers)
+	require.Error(t, err)
+}
+
+func TestBuildUserDefaultProviders(t *testing.T) {
+	t.Parallel()
+
+	opts := &openfga.OasFGAAuthzOptions{User: openfga.OasFGAAuthzOption{Namespace: "user"}}
+	ctx := oidc.WithJWT(context.Background(), &oas.AuthClaims{Subject: "alice"})
+
+	require.Equal(t, openfga.User("user:alice"), openfga.BuildUser(ctx, nil, opts))
+	require.Equal(t, openfga.NoopUser, openfga.BuildUser(context.Background(), nil, opts))
+}
+
+const userSpec = `
+openapi: 3.0.0
+info:
+  title: test
+  version: 1.0.0
+components:
+  securitySchemes:
+    bearer:
+      type: http
+      scheme: bearer
+paths:
+  /accounts:
+    get:
+      security:
+        - bearer: []
+      x-fiber-authz-fga:
+        user:
+          namespace: user
+        relation:
+          name: reader
+        object:
+          namespace: system
+          name: accounts
+      responses:
+        "200":
+          description: ok
+`
+
+func TestOasAuthenticateStatus(t *testing.T) {
+	t.Parallel()
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(userSpec))
+	require.NoError(t, err)
+
+	checker := checkerFunc(func(_ context.Context, user openfga.User, _ openfga.Relation, _ openfga.Object) (bool, error) {
+		return user == "user:alice", nil
+	})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if sub := c.Get("X-User"); sub != "" {
+			c.SetUserContext(oidc.WithJWT(c.UserContext(), &oas.AuthClaims{Subject: sub}))
+		}
+
+		return c.Next()
+	})
+	app.Use(middleware.OapiRequestValidatorWithOptions(doc, &middleware.Options{
+		Options: openapi3filter.Options{
+			AuthenticationFunc: openfga.OasAuthenticate(openfga.WithChecker(checker)),
+		},
+		ErrorHandler: authz.NewOpenAPIErrorHandler(),
+	}))
+	app.Get("/accounts", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		user   string
+		status int
+	}{
+		{name: "allowed", user: "alice", status: fiber.StatusOK},
+		{name: "no identity", status: fiber.StatusUnauthorized},
+		{name: "forbidden", user: "bob", status: fiber.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/accounts", nil)
+			req.Header.Set("X-User", tt.user)
+
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, tt.status, res.StatusCode)
+		})
+	}
+}
+
+func TestOasAuthenticateDecision(t *testing.T) {
+	t.Parallel()
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(userSpec))
+	require.NoError(t, err)
+
+	checker := checkerFunc(func(context.Context, openfga.User, openfga.Relation, openfga.Object) (bool, error) {
+		return false, nil
+	})
+
+	var decision authz.Decision
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.SetUserContext(oidc.WithJWT(c.UserContext(), &oas.AuthClaims{Subject: "bob"}))
+
+		return c.Next()
+	})
+	app.Use(middleware.OapiRequestValidatorWithOptions(doc, &middleware.Options{
+		Options: openapi3filter.Options{
+			AuthenticationFunc: openfga.OasAuthenticate(openfga.WithChecker(checker)),
+		},
+		ErrorHandler: func(c *fiber.Ctx, message string, statusCode int) {
+			decision, _ = authz.GetAuthzDecision(c.UserContext())
+			authz.NewOpenAPIErrorHandler()(c, message, statusCode)
+		},
+	}))
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/accounts", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusForbidden, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NotContains(t, string(body), "user:bob")
+
+	require.False(t, decision.Allowed)
+	require.Equal(t, "failed checks: user:bob reader system:accounts", decision.Reason)
+}
+
+func TestOasAuthenticateExtension(t *testing.T) {
+	t.Parallel()
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(strings.ReplaceAll(userSpec, openfga.DefaultExtensionName, "x-acme-authz")))
+	require.NoError(t, err)
+
+	checker := checkerFunc(func(_ context.Context, user openfga.User, relation openfga.Relation, object openfga.Object) (bool, error) {
+		return user == "user:alice" && relation == "reader" && object == "system:accounts", nil
+	})
+
+	tests := []struct {
+		name string
+		opts []openfga.OasAuthenticateOpt
+	}{
+		{
+			name: "default builder",
+			opts: []openfga.OasAuthenticateOpt{openfga.WithChecker(checker), openfga.WithExtension("x-acme-authz")},
+		},
+		{
+			name: "builder after extension",
+			opts: []openfga.OasAuthenticateOpt{openfga.WithChecker(checker), openfga.WithExtension("x-acme-authz"), openfga.WithBuilder(openfga.NewOasFGAAuthzBuilder())},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				c.SetUserContext(oidc.WithJWT(c.UserContext(), &oas.AuthClaims{Subject: c.Get("X-User")}))
+
+				return c.Next()
+			})
+			app.Use(middleware.OapiRequestValidatorWithOptions(doc, &middleware.Options{
+				Options: openapi3filter.Options{
+					AuthenticationFunc: openfga.OasAuthenticate(tt.opts...),
+				},
+				ErrorHandler: authz.NewOpenAPIErrorHandler(),
+			}))
+			app.Get("/accounts", func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			for user, status := range map[string]int{"alice": fiber.StatusOK, "bob": fiber.StatusForbidden} {
+				req := httptest.NewRequest(http.MethodGet, "/accounts", nil)
+				req.Header.Set("X-User", user)
+
+				res, err := app.Test(req)
+				require.NoError(t, err)
+				require.Equal(t, status, res.StatusCode, user)
+			}
+		})
+	}
+}
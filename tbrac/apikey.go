@@ -0,0 +1,44 @@
+package tbrac
+
+import (
+	"context"
+	"errors"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/zeiss/fiber-authz/openfga"
+	"gorm.io/gorm"
+)
+
+// DefaultAPIKeyHeader is the default header of the API key.
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// NewAPIKeyUserProvider returns a user provider that resolves the API key
+// of the request to the id of the API key in the store.
+//
+// It is registered for the openfga.AuthTypeAPIKey with openfga.WithUserProvider.
+func NewAPIKeyUserProvider(db *gorm.DB, header ...string) openfga.UserProvider {
+	h := DefaultAPIKeyHeader
+	if len(header) > 0 {
+		h = header[0]
+	}
+
+	return func(ctx context.Context, input *openapi3filter.AuthenticationInput, _ openfga.OasFGAAuthzOption) (string, error) {
+		key := input.RequestValidationInput.Request.Header.Get(h)
+		if key == "" {
+			return "", openfga.ErrNoIdentity
+		}
+
+		var apiKey APIKey
+
+		err := db.WithContext(ctx).Where("key = ?", key).First(&apiKey).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", openfga.ErrNoIdentity
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		return apiKey.ID.String(), nil
+	}
+}
@@ -0,0 +1,80 @@
+package tbrac
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/zeiss/fiber-authz/openfga"
+)
+
+func TestAPIKeyUserProvider(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+
+	active := APIKey{ID: uuid.New(), Key: "active"}
+	require.NoError(t, db.Create(&active).Error)
+
+	deleted := APIKey{ID: uuid.New(), Key: "deleted"}
+	require.NoError(t, db.Create(&deleted).Error)
+	require.NoError(t, db.Delete(&deleted).Error)
+
+	tests := []struct {
+		name   string
+		header string
+		key    string
+		id     string
+		err    error
+	}{
+		{
+			name: "valid key",
+			key:  "active",
+			id:   active.ID.String(),
+		},
+		{
+			name:   "custom header",
+			header: "X-Token",
+			key:    "active",
+			id:     active.ID.String(),
+		},
+		{
+			name: "unknown key",
+			key:  "unknown",
+			err:  openfga.ErrNoIdentity,
+		},
+		{
+			name: "deleted key",
+			key:  "deleted",
+			err:  openfga.ErrNoIdentity,
+		},
+		{
+			name: "no key",
+			err:  openfga.ErrNoIdentity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, header := DefaultAPIKeyHeader, []string{}
+			if tt.header != "" {
+				name, header = tt.header, []string{tt.header}
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(name, tt.key)
+
+			input := &openapi3filter.AuthenticationInput{
+				RequestValidationInput: &openapi3filter.RequestValidationInput{Request: req},
+			}
+
+			id, err := NewAPIKeyUserProvider(db, header...)(context.Background(), input, openfga.OasFGAAuthzOption{})
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.id, id)
+		})
+	}
+}
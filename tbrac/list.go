@@ -0,0 +1,54 @@
+package tbrac
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	authz "github.com/zeiss/fiber-authz"
+	"github.com/zeiss/fiber-authz/openfga"
+)
+
+// TeamLister lists the teams in which a principal has a permission.
+type TeamLister interface {
+	// ListTeams returns the slugs of the teams in which the principal has the permission.
+	ListTeams(ctx context.Context, principal authz.AuthzPrincipal, permission authz.AuthzAction) ([]string, error)
+}
+
+var _ TeamLister = (*tbac)(nil)
+
+// ListTeams returns the slugs of the teams in which the principal has the permission.
+// Deleted teams are not listed.
+func (t *tbac) ListTeams(ctx context.Context, principal authz.AuthzPrincipal, permission authz.AuthzAction) ([]string, error) {
+	teamsTableName := t.db.Config.NamingStrategy.TableName("teams")
+
+	slugs := []string{}
+
+	err := t.db.WithContext(ctx).Raw("SELECT DISTINCT T.slug FROM vw_user_team_permissions AS A JOIN "+teamsTableName+" AS T ON A.team_id = T.id WHERE A.user_id = ? AND A.permission = ? AND T.deleted_at IS NULL ORDER BY T.slug", principal, permission).Scan(&slugs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return slugs, nil
+}
+
+// ListTeams is a middleware that lists the slugs of the teams in which the principal has the permission.
+// The slugs are stored in the user context, see openfga.GetAllowedObjects and openfga.Filter.
+func ListTeams(lister TeamLister, permission authz.AuthzAction, resolver authz.AuthzPrincipalResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, err := resolver.Resolve(c)
+		if err != nil || principal == authz.AuthzNoPrincipial {
+			return fiber.ErrUnauthorized
+		}
+
+		// nolint:contextcheck
+		slugs, err := lister.ListTeams(c.UserContext(), principal, permission)
+		if err != nil {
+			return err
+		}
+
+		// nolint:contextcheck
+		c.SetUserContext(openfga.WithAllowedObjects(c.UserContext(), slugs))
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,55 @@
+package tbrac
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	authz "github.com/zeiss/fiber-authz"
+)
+
+func TestListTeams(t *testing.T) {
+	t.Parallel()
+
+	checker := NewTBAC(newTestDB(t))
+
+	tests := []struct {
+		name       string
+		principal  authz.AuthzPrincipal
+		permission authz.AuthzAction
+		teams      []string
+	}{
+		{
+			name:       "deleted team",
+			principal:  authz.AuthzPrincipal(alice.String()),
+			permission: "write",
+			teams:      []string{"alpha"},
+		},
+		{
+			name:       "read permission",
+			principal:  authz.AuthzPrincipal(alice.String()),
+			permission: "read",
+			teams:      []string{"alpha"},
+		},
+		{
+			name:       "no permission",
+			principal:  authz.AuthzPrincipal(bob.String()),
+			permission: "write",
+			teams:      []string{},
+		},
+		{
+			name:       "unknown principal",
+			principal:  "alice",
+			permission: "read",
+			teams:      []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teams, err := checker.ListTeams(context.Background(), tt.principal, tt.permission)
+			require.NoError(t, err)
+			require.Equal(t, tt.teams, teams)
+		})
+	}
+}
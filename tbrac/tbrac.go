@@ -2,6 +2,8 @@ package tbrac
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -25,26 +27,20 @@ func RunMigrations(db *gorm.DB) error {
 		&UserRole{},
 		&APIKey{},
 		&APIKeyRole{},
+		&CasbinRule{},
 	)
 	if err != nil {
 		return err
 	}
 
-	userRolesTableName := db.Config.NamingStrategy.TableName("user_roles")
-	apiKeyRolesTableName := db.Config.NamingStrategy.TableName("api_key_roles")
-	rolePermissionsTableName := db.Config.NamingStrategy.TableName("role_permissions")
-	permissionsTableName := db.Config.NamingStrategy.TableName("permissions")
-
 	// View for user team permissions
-	query := db.Raw("SELECT A.user_id, A.team_id, C.scope as permission FROM " + userRolesTableName + " AS A LEFT JOIN " + rolePermissionsTableName + " AS B ON A.role_id = B.role_id LEFT JOIN " + permissionsTableName + " AS C on B.permission_id = C.id;")
-	err = db.Migrator().CreateView("vw_user_team_permissions", gorm.ViewOption{Query: query, Replace: true})
+	err = db.Migrator().CreateView("vw_user_team_permissions", gorm.ViewOption{Query: userTeamPermissionsQuery(db), Replace: true})
 	if err != nil {
 		return err
 	}
 
 	// View for the api key permissions
-	query = db.Raw("SELECT A.key_id, A.team_id, C.scope as permission FROM " + apiKeyRolesTableName + " AS A LEFT JOIN " + rolePermissionsTableName + " AS B ON A.role_id = B.role_id LEFT JOIN " + permissionsTableName + " AS C on B.permission_id = C.id;")
-	err = db.Migrator().CreateView("vw_api_key_team_permissions", gorm.ViewOption{Query: query, Replace: true})
+	err = db.Migrator().CreateView("vw_api_key_team_permissions", gorm.ViewOption{Query: apiKeyTeamPermissionsQuery(db), Replace: true})
 	if err != nil {
 		return err
 	}
@@ -52,6 +48,22 @@ func RunMigrations(db *gorm.DB) error {
 	return nil
 }
 
+func userTeamPermissionsQuery(db *gorm.DB) *gorm.DB {
+	userRolesTableName := db.Config.NamingStrategy.TableName("user_roles")
+	rolePermissionsTableName := db.Config.NamingStrategy.TableName("role_permissions")
+	permissionsTableName := db.Config.NamingStrategy.TableName("permissions")
+
+	return db.Raw("SELECT A.user_id, A.team_id, C.scope as permission FROM " + userRolesTableName + " AS A LEFT JOIN " + rolePermissionsTableName + " AS B ON A.role_id = B.role_id LEFT JOIN " + permissionsTableName + " AS C on B.permission_id = C.id;")
+}
+
+func apiKeyTeamPermissionsQuery(db *gorm.DB) *gorm.DB {
+	apiKeyRolesTableName := db.Config.NamingStrategy.TableName("api_key_roles")
+	rolePermissionsTableName := db.Config.NamingStrategy.TableName("role_permissions")
+	permissionsTableName := db.Config.NamingStrategy.TableName("permissions")
+
+	return db.Raw("SELECT A.key_id, A.team_id, C.scope as permission FROM " + apiKeyRolesTableName + " AS A LEFT JOIN " + rolePermissionsTableName + " AS B ON A.role_id = B.role_id LEFT JOIN " + permissionsTableName + " AS C on B.permission_id = C.id;")
+}
+
 // Role is a role that a user can have.
 type Role struct {
 	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid()"`
@@ -203,9 +215,26 @@ type APIKeyRole struct {
 	DeletedAt gorm.DeletedAt
 }
 
+// CasbinRule is a Casbin policy rule.
+type CasbinRule struct {
+	// ID is the primary key of the rule.
+	ID uint `gorm:"primaryKey;autoIncrement"`
+	// Ptype is the policy type of the rule (e.g. p or g).
+	Ptype string `gorm:"size:100;uniqueIndex:idx_casbin_rule"`
+	// V0 to V5 are the values of the rule.
+	V0 string `gorm:"size:100;uniqueIndex:idx_casbin_rule"`
+	V1 string `gorm:"size:100;uniqueIndex:idx_casbin_rule"`
+	V2 string `gorm:"size:100;uniqueIndex:idx_casbin_rule"`
+	V3 string `gorm:"size:100;uniqueIndex:idx_casbin_rule"`
+	V4 string `gorm:"size:100;uniqueIndex:idx_casbin_rule"`
+	V5 string `gorm:"size:100;uniqueIndex:idx_casbin_rule"`
+}
+
 var (
-	_ authz.AuthzChecker = (*tbac)(nil)
-	_ adapters.Adapter   = (*tbac)(nil)
+	_ authz.AuthzChecker    = (*tbac)(nil)
+	_ authz.DecisionChecker = (*tbac)(nil)
+	_ authz.BatchChecker    = (*tbac)(nil)
+	_ adapters.Adapter      = (*tbac)(nil)
 )
 
 type tbac struct {
@@ -223,20 +252,99 @@ func NewTBAC(db *gorm.DB) *tbac {
 
 // Allowed is a method that returns true if the principal is allowed to perform the action on the user.
 func (t *tbac) Allowed(ctx context.Context, principal authz.AuthzPrincipal, object authz.AuthzObject, action authz.AuthzAction) (bool, error) {
+	decision, err := t.Decide(ctx, principal, object, action)
+	if err != nil {
+		return false, err
+	}
+
+	return decision.Allowed, nil
+}
+
+// Decide is a method that returns the decision if the principal has the permission in the team.
+// The matched policy is the permission that has been checked.
+func (t *tbac) Decide(ctx context.Context, principal authz.AuthzPrincipal, object authz.AuthzObject, action authz.AuthzAction) (authz.Decision, error) {
+	start := time.Now()
+
 	var allowed int64
 
 	team := t.db.WithContext(ctx).Model(&Team{}).Select("id").Where("slug = ?", object)
 
 	err := t.db.Raw("SELECT COUNT(1) FROM vw_user_team_permissions WHERE user_id = ? AND team_id = (?) AND permission = ?", principal, team, action).Count(&allowed).Error
 	if err != nil {
-		return false, err
+		return authz.Decision{}, err
 	}
 
-	if allowed > 0 {
-		return true, nil
+	decision := tbacDecision(principal, object, action, allowed > 0)
+	decision.Duration = time.Since(start)
+
+	return decision, nil
+}
+
+// BatchDecide is a method that returns a decision for every check with a single query.
+func (t *tbac) BatchDecide(ctx context.Context, checks []authz.AuthzParams) ([]authz.Decision, error) {
+	if len(checks) == 0 {
+		return []authz.Decision{}, nil
+	}
+
+	start := time.Now()
+
+	teamsTableName := t.db.Config.NamingStrategy.TableName("teams")
+
+	type grant struct {
+		UserID     uuid.UUID
+		Slug       string
+		Permission string
+	}
+
+	conds := make([]string, 0, len(checks))
+	args := make([]interface{}, 0, len(checks)*3)
+	keys := make([]grant, len(checks))
+
+	for i, check := range checks {
+		// principals that are not a uuid have no permissions.
+		id, err := uuid.Parse(check.Principal.String())
+		if err != nil {
+			continue
+		}
+
+		keys[i] = grant{UserID: id, Slug: check.Object.String(), Permission: check.Action.String()}
+
+		conds = append(conds, "(A.user_id = ? AND T.slug = ? AND A.permission = ?)")
+		args = append(args, id, check.Object, check.Action)
 	}
 
-	return false, nil
+	var rows []grant
+
+	if len(conds) > 0 {
+		err := t.db.WithContext(ctx).Raw("SELECT A.user_id, T.slug, A.permission FROM vw_user_team_permissions AS A JOIN "+teamsTableName+" AS T ON A.team_id = T.id WHERE T.deleted_at IS NULL AND ("+strings.Join(conds, " OR ")+")", args...).Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	granted := make(map[grant]bool, len(rows))
+	for _, row := range rows {
+		granted[row] = true
+	}
+
+	decisions := make([]authz.Decision, len(checks))
+	for i, check := range checks {
+		decisions[i] = tbacDecision(check.Principal, check.Object, check.Action, keys[i].UserID != uuid.Nil && granted[keys[i]])
+		decisions[i].Duration = time.Since(start)
+	}
+
+	return decisions, nil
+}
+
+func tbacDecision(principal authz.AuthzPrincipal, object authz.AuthzObject, action authz.AuthzAction, allowed bool) authz.Decision {
+	decision := authz.Deny("tbac", fmt.Sprintf("%s has no permission %s in team %s", principal, action, object))
+	if allowed {
+		decision = authz.Allow("tbac", fmt.Sprintf("%s has permission %s in team %s", principal, action, object))
+	}
+
+	decision.Policy = action.String()
+
+	return decision
 }
 
 // Resolve ...
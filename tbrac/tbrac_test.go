@@ -1,9 +1,16 @@
 package tbrac
 
 import (
+	"context"
+	"path/filepath"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	authz "github.com/zeiss/fiber-authz"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 func TestTeam(t *testing.T) {
@@ -73,3 +80,84 @@ func TestTeam(t *testing.T) {
 		})
 	}
 }
+
+var (
+	alice = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	bob   = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+)
+
+// newTestDB returns a sqlite database with the tables and views of the permissions.
+// The migrations use postgres defaults, so the tables are created manually.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "tbrac.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+
+	for _, stmt := range []string{
+		"CREATE TABLE teams (id TEXT PRIMARY KEY, slug TEXT, deleted_at DATETIME)",
+		"CREATE TABLE permissions (id INTEGER PRIMARY KEY, scope TEXT)",
+		"CREATE TABLE role_permissions (role_id TEXT, permission_id INTEGER)",
+		"CREATE TABLE user_roles (user_id TEXT, team_id TEXT, role_id TEXT)",
+		"CREATE TABLE api_key_roles (key_id TEXT, team_id TEXT, role_id TEXT)",
+		"CREATE TABLE api_keys (id TEXT PRIMARY KEY, key TEXT, description TEXT, created_at DATETIME, updated_at DATETIME, deleted_at DATETIME)",
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	require.NoError(t, db.Migrator().CreateView("vw_user_team_permissions", gorm.ViewOption{Query: userTeamPermissionsQuery(db)}))
+	require.NoError(t, db.Migrator().CreateView("vw_api_key_team_permissions", gorm.ViewOption{Query: apiKeyTeamPermissionsQuery(db)}))
+
+	// alice is a writer in the teams "alpha" and "gamma", bob is a reader in "alpha".
+	// The team "gamma" has been deleted.
+	writer, reader := uuid.New(), uuid.New()
+	alpha, beta, gamma := uuid.New(), uuid.New(), uuid.New()
+
+	for _, stmt := range []struct {
+		sql  string
+		args []interface{}
+	}{
+		{"INSERT INTO teams (id, slug) VALUES (?, ?), (?, ?)", []interface{}{alpha, "alpha", beta, "beta"}},
+		{"INSERT INTO teams (id, slug, deleted_at) VALUES (?, ?, CURRENT_TIMESTAMP)", []interface{}{gamma, "gamma"}},
+		{"INSERT INTO permissions (id, scope) VALUES (1, 'read'), (2, 'write')", nil},
+		{"INSERT INTO role_permissions (role_id, permission_id) VALUES (?, 1), (?, 2), (?, 1)", []interface{}{writer, writer, reader}},
+		{"INSERT INTO user_roles (user_id, team_id, role_id) VALUES (?, ?, ?), (?, ?, ?), (?, ?, ?)", []interface{}{alice, alpha, writer, alice, gamma, writer, bob, alpha, reader}},
+	} {
+		require.NoError(t, db.Exec(stmt.sql, stmt.args...).Error)
+	}
+
+	return db
+}
+
+func TestBatchDecide(t *testing.T) {
+	t.Parallel()
+
+	checker := NewTBAC(newTestDB(t))
+
+	checks := []authz.AuthzParams{
+		{Principal: authz.AuthzPrincipal(alice.String()), Object: "alpha", Action: "write"},
+		{Principal: authz.AuthzPrincipal(bob.String()), Object: "alpha", Action: "read"},
+		{Principal: authz.AuthzPrincipal(bob.String()), Object: "alpha", Action: "write"},
+		{Principal: authz.AuthzPrincipal(alice.String()), Object: "beta", Action: "read"},
+		{Principal: authz.AuthzPrincipal(alice.String()), Object: "gamma", Action: "write"},
+		{Principal: "alice", Object: "alpha", Action: "write"},
+	}
+
+	decisions, err := checker.BatchDecide(context.Background(), checks)
+	require.NoError(t, err)
+	require.Len(t, decisions, len(checks))
+
+	allowed := make([]bool, len(decisions))
+	for i, d := range decisions {
+		allowed[i] = d.Allowed
+		require.Equal(t, checks[i].Action.String(), d.Policy)
+	}
+
+	require.Equal(t, []bool{true, true, false, false, false, false}, allowed)
+
+	for i, check := range checks {
+		decision, err := checker.Decide(context.Background(), check.Principal, check.Object, check.Action)
+		require.NoError(t, err)
+		require.Equal(t, allowed[i], decision.Allowed, check)
+	}
+}